@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// How long a problem is remembered after it was last seen with the same
+// filters. Filters not shown for that long, e.g. a view of a playlist, are
+// forgotten completely and start over without alerts.
+const forgetProblemsAfter = 2 * time.Minute
+
+// problemTracker remembers when critical problems appeared first, to mark
+// problems that are new since the previous snapshots. Dashboards with
+// different filters see different problems, so every filter set is tracked
+// on its own.
+type problemTracker struct {
+	mu      sync.Mutex
+	filters map[string]*trackedProblems
+}
+
+type trackedProblems struct {
+	firstSeen map[string]time.Time
+	lastSeen  map[string]time.Time
+	observed  time.Time
+}
+
+func newProblemTracker() *problemTracker {
+	return &problemTracker{filters: make(map[string]*trackedProblems)}
+}
+
+// observe records the problem keys seen with the given filters and returns
+// when each of them was seen first. Problems present in the first snapshot of
+// a filter set are reported with a zero time, so restarting the dashboard or
+// opening another view doesn't raise alerts for old problems.
+func (p *problemTracker) observe(filters string, keys []string, currentTime time.Time) map[string]time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for name, tracked := range p.filters {
+		if currentTime.Sub(tracked.observed) > forgetProblemsAfter {
+			delete(p.filters, name)
+		}
+	}
+	tracked, initialized := p.filters[filters]
+	if !initialized {
+		tracked = &trackedProblems{
+			firstSeen: make(map[string]time.Time),
+			lastSeen:  make(map[string]time.Time),
+		}
+		p.filters[filters] = tracked
+	}
+	tracked.observed = currentTime
+
+	for key, lastSeen := range tracked.lastSeen {
+		if currentTime.Sub(lastSeen) > forgetProblemsAfter {
+			delete(tracked.lastSeen, key)
+			delete(tracked.firstSeen, key)
+		}
+	}
+
+	result := make(map[string]time.Time)
+	for _, key := range keys {
+		firstSeen, ok := tracked.firstSeen[key]
+		if !ok {
+			if initialized {
+				firstSeen = currentTime
+			}
+			tracked.firstSeen[key] = firstSeen
+		}
+		tracked.lastSeen[key] = currentTime
+		result[key] = firstSeen
+	}
+
+	return result
+}
+
+// alertSettings control how the dashboard draws attention to new critical problems.
+type alertSettings struct {
+	// How long a problem is marked as new
+	NewDuration time.Duration
+	// URL of the sound to play, a beep is generated if empty
+	SoundURL string
+	Sound    bool
+	Flash    bool
+	Quiet    quietHours
+}
+
+// quietHours is a daily time range in minutes after midnight. The range may
+// wrap around midnight, e.g. 22:00-07:00.
+type quietHours struct {
+	Start   int
+	End     int
+	Enabled bool
+}
+
+func parseQuietHours(value string) (quietHours, error) {
+	if value == "" {
+		return quietHours{}, nil
+	}
+
+	var startHour, startMinute, endHour, endMinute int
+	_, err := fmt.Sscanf(value, "%d:%d-%d:%d", &startHour, &startMinute, &endHour, &endMinute)
+	if err != nil || startHour > 23 || endHour > 23 || startMinute > 59 || endMinute > 59 ||
+		startHour < 0 || endHour < 0 || startMinute < 0 || endMinute < 0 {
+		return quietHours{}, fmt.Errorf("invalid quiet hours %q, expected HH:MM-HH:MM", value)
+	}
+
+	return quietHours{
+		Start:   startHour*60 + startMinute,
+		End:     endHour*60 + endMinute,
+		Enabled: true,
+	}, nil
+}
+
+func (q quietHours) contains(t time.Time) bool {
+	if !q.Enabled {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start <= q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+func hostAlertKey(name string) string {
+	return "host!" + name
+}
+
+func serviceAlertKeys(record PageServiceListRecord) []string {
+	var keys []string
+	for _, member := range record.Members {
+		keys = append(keys, "service!"+member.Host+"!"+member.Service)
+	}
+	return keys
+}
+
+// markNewProblems flags hosts that are down and services that are critical
+// if they appeared within settings.NewDuration with the same filters. Without
+// a tracker, e.g. for API requests, nothing is new.
+func markNewProblems(pageVariables *PageVariables, tracker *problemTracker, filters string, settings alertSettings, currentTime time.Time) {
+	// A failed snapshot misses problems, they didn't recover
+	if tracker == nil || pageVariables.Error != nil {
+		return
+	}
+
+	var keys []string
+	for _, host := range pageVariables.HostRecords {
+		if host.State == 1 {
+			keys = append(keys, hostAlertKey(host.Name))
+		}
+	}
+	for _, service := range pageVariables.ServiceRecords {
+		if service.State == 2 {
+			keys = append(keys, serviceAlertKeys(service)...)
+		}
+	}
+
+	firstSeen := tracker.observe(filters, keys, currentTime)
+	isNew := func(key string) bool {
+		seen, ok := firstSeen[key]
+		return ok && !seen.IsZero() && currentTime.Sub(seen) < settings.NewDuration
+	}
+
+	for i, host := range pageVariables.HostRecords {
+		if isNew(hostAlertKey(host.Name)) {
+			pageVariables.HostRecords[i].IsNew = true
+			pageVariables.NewProblems++
+		}
+	}
+	for i, service := range pageVariables.ServiceRecords {
+		for _, key := range serviceAlertKeys(service) {
+			if isNew(key) {
+				pageVariables.ServiceRecords[i].IsNew = true
+				pageVariables.NewProblems++
+				break
+			}
+		}
+	}
+}
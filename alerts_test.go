@@ -0,0 +1,174 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProblemTracker_Observe(t *testing.T) {
+	tracker := newProblemTracker()
+	start := time.Unix(1000, 0)
+
+	firstSeen := tracker.observe("", []string{"host!a"}, start)
+	if !firstSeen["host!a"].IsZero() {
+		t.Errorf("expected problems of the first snapshot not to be new, got %v", firstSeen["host!a"])
+	}
+
+	firstSeen = tracker.observe("", []string{"host!a", "host!b"}, start.Add(5*time.Second))
+	if !firstSeen["host!a"].IsZero() {
+		t.Errorf("expected host!a to keep its first seen time, got %v", firstSeen["host!a"])
+	}
+	if !firstSeen["host!b"].Equal(start.Add(5 * time.Second)) {
+		t.Errorf("expected host!b to be seen first now, got %v", firstSeen["host!b"])
+	}
+
+	// Problems not seen for a while are forgotten and new again when they reappear
+	later := start.Add(5*time.Second + forgetProblemsAfter + time.Second)
+	tracker.observe("", nil, later)
+	firstSeen = tracker.observe("", []string{"host!a"}, later)
+	if !firstSeen["host!a"].Equal(later) {
+		t.Errorf("expected host!a to be new again, got %v", firstSeen["host!a"])
+	}
+}
+
+func TestProblemTracker_ObservePerFilters(t *testing.T) {
+	tracker := newProblemTracker()
+	start := time.Unix(1000, 0)
+
+	tracker.observe("hostGroup=db", []string{"host!db-1"}, start)
+	// A wider dashboard opened later knows nothing about its old problems
+	firstSeen := tracker.observe("hostGroup=", []string{"host!db-1", "host!web-1"}, start.Add(time.Minute))
+	if !firstSeen["host!db-1"].IsZero() || !firstSeen["host!web-1"].IsZero() {
+		t.Errorf("expected the first snapshot of other filters not to be new, got %v", firstSeen)
+	}
+
+	// A view of a playlist shown again after a while starts over
+	later := start.Add(time.Minute + forgetProblemsAfter + time.Second)
+	tracker.observe("hostGroup=", []string{"host!db-1", "host!web-1"}, later)
+	firstSeen = tracker.observe("hostGroup=db", []string{"host!db-1", "host!db-2"}, later)
+	if !firstSeen["host!db-1"].IsZero() || !firstSeen["host!db-2"].IsZero() {
+		t.Errorf("expected a view not shown for a while not to raise alerts, got %v", firstSeen)
+	}
+}
+
+func TestAPIRequestsDontTrackProblems(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+	originalProblems := problems
+	t.Cleanup(func() { problems = originalProblems })
+	problems = newProblemTracker()
+
+	renderJSON(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/dashboard", nil))
+	serveAPIv2(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v2/services", nil))
+	renderCSV(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/dashboard.csv", nil))
+	if len(problems.filters) != 0 {
+		t.Errorf("expected API requests not to change the alert state, got %+v", problems.filters)
+	}
+
+	renderDashboard(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(problems.filters) != 1 {
+		t.Errorf("expected the dashboard to track its problems, got %+v", problems.filters)
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	quiet, err := parseQuietHours("22:30-07:00")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if quiet.Start != 22*60+30 || quiet.End != 7*60 || !quiet.Enabled {
+		t.Errorf("unexpected quiet hours %+v", quiet)
+	}
+
+	if quiet, err := parseQuietHours(""); err != nil || quiet.Enabled {
+		t.Errorf("expected disabled quiet hours, got %+v (%v)", quiet, err)
+	}
+	for _, value := range []string{"22:00", "25:00-07:00", "22:00-07:61", "late"} {
+		if _, err := parseQuietHours(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
+func TestQuietHours_Contains(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, time.March, 11, hour, minute, 0, 0, time.UTC)
+	}
+
+	overnight := quietHours{Start: 22 * 60, End: 7 * 60, Enabled: true}
+	if !overnight.contains(at(23, 0)) || !overnight.contains(at(3, 0)) || overnight.contains(at(7, 0)) || overnight.contains(at(12, 0)) {
+		t.Errorf("unexpected result for overnight quiet hours")
+	}
+
+	lunch := quietHours{Start: 12 * 60, End: 13 * 60, Enabled: true}
+	if !lunch.contains(at(12, 30)) || lunch.contains(at(13, 0)) || lunch.contains(at(11, 59)) {
+		t.Errorf("unexpected result for quiet hours within a day")
+	}
+
+	if (quietHours{}).contains(at(12, 0)) {
+		t.Errorf("expected disabled quiet hours never to match")
+	}
+}
+
+func TestQuietHoursInDashboardTimezone(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+	originalAlerts := alerts
+	defer func() { alerts = originalAlerts }()
+	quiet, err := parseQuietHours("22:00-06:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alerts = alertSettings{Sound: true, Quiet: quiet}
+	// 22:30 in Berlin
+	now = func() time.Time { return time.Date(2026, time.March, 11, 21, 30, 0, 0, time.UTC) }
+
+	page := buildPageVariables(httptest.NewRequest(http.MethodGet, "/?tz=Europe/Berlin", nil), nil)
+	if page.AlertSound {
+		t.Errorf("expected sounds to be muted during the quiet hours in Berlin")
+	}
+	page = buildPageVariables(httptest.NewRequest(http.MethodGet, "/?tz=UTC", nil), nil)
+	if !page.AlertSound {
+		t.Errorf("expected sounds outside of the quiet hours in UTC")
+	}
+}
+
+func TestMarkNewProblems(t *testing.T) {
+	tracker := newProblemTracker()
+	settings := alertSettings{NewDuration: time.Minute}
+	start := time.Unix(1000, 0)
+
+	newPage := func() PageVariables {
+		return PageVariables{
+			HostRecords: []PageHostListRecord{{Name: "old-host", State: 1}},
+			ServiceRecords: []PageServiceListRecord{
+				{Name: "disk", State: 2, Members: []PageServiceMember{{Host: "a", Service: "disk"}}},
+				{Name: "load", State: 1, Members: []PageServiceMember{{Host: "a", Service: "load"}}},
+			},
+		}
+	}
+
+	page := newPage()
+	markNewProblems(&page, tracker, "", settings, start)
+	if page.NewProblems != 0 {
+		t.Errorf("expected no new problems on startup, got %d", page.NewProblems)
+	}
+
+	page = newPage()
+	page.HostRecords = append(page.HostRecords, PageHostListRecord{Name: "new-host", State: 1})
+	page.ServiceRecords[0].Members = append(page.ServiceRecords[0].Members, PageServiceMember{Host: "b", Service: "disk"})
+	markNewProblems(&page, tracker, "", settings, start.Add(5*time.Second))
+	if page.NewProblems != 2 || page.HostRecords[0].IsNew || !page.HostRecords[1].IsNew || !page.ServiceRecords[0].IsNew {
+		t.Errorf("expected new-host and disk on b to be new, got %+v", page)
+	}
+	if page.ServiceRecords[1].IsNew {
+		t.Errorf("expected warnings never to be new")
+	}
+
+	page = newPage()
+	page.HostRecords = append(page.HostRecords, PageHostListRecord{Name: "new-host", State: 1})
+	markNewProblems(&page, tracker, "", settings, start.Add(5*time.Second+time.Minute))
+	if page.NewProblems != 0 {
+		t.Errorf("expected problems to stop being new after the configured time, got %d", page.NewProblems)
+	}
+}
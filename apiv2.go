@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// The v2 API serves the dashboard data as separate resources with a stable
+// schema, documented in openapi.json. Unlike /api/v1/dashboard it contains no
+// display text and reports errors as error objects.
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type apiErrorResponse struct {
+	Error apiError `json:"error"`
+}
+
+type apiList[T any] struct {
+	Items []T `json:"items"`
+	// Number of items matching the filters, on all pages
+	Total      int  `json:"total"`
+	Offset     int  `json:"offset"`
+	Limit      int  `json:"limit"`
+	NextOffset *int `json:"next_offset"`
+}
+
+type apiHost struct {
+	Name             string   `json:"name"`
+	State            int      `json:"state"`
+	StateName        string   `json:"state_name"`
+	StateType        int      `json:"state_type"`
+	StateTypeName    string   `json:"state_type_name"`
+	UnreachableHosts []string `json:"unreachable_hosts"`
+	LastStateChange  *string  `json:"last_state_change"`
+	Priority         string   `json:"priority"`
+	IsNew            bool     `json:"is_new"`
+}
+
+type apiService struct {
+	Name            string   `json:"name"`
+	Group           string   `json:"group"`
+	State           int      `json:"state"`
+	StateName       string   `json:"state_name"`
+	StateType       int      `json:"state_type"`
+	StateTypeName   string   `json:"state_type_name"`
+	Hosts           []string `json:"hosts"`
+	Services        []string `json:"services"`
+	IsAggregated    bool     `json:"is_aggregated"`
+	Since           *string  `json:"since"`
+	LastStateChange *string  `json:"last_state_change"`
+	Priority        string   `json:"priority"`
+	Weight          int      `json:"weight"`
+	IsNew           bool     `json:"is_new"`
+}
+
+type apiSummary struct {
+	Time                 string           `json:"time"`
+	Timezone             string           `json:"timezone"`
+	NotificationsEnabled bool             `json:"notifications_enabled"`
+	DisabledFeatures     []string         `json:"disabled_features"`
+	Hosts                apiHostCounts    `json:"hosts"`
+	Services             apiServiceCounts `json:"services"`
+	Problems             apiProblemCounts `json:"problems"`
+	Icinga               apiIcinga        `json:"icinga"`
+}
+
+type apiIcinga struct {
+	Version           string  `json:"version"`
+	NodeName          string  `json:"node_name"`
+	Environment       string  `json:"environment"`
+	PID               int     `json:"pid"`
+	ProgramStart      *string `json:"program_start"`
+	Uptime            int     `json:"uptime"`
+	RecentlyRestarted bool    `json:"recently_restarted"`
+}
+
+type apiHostCounts struct {
+	Up                 int     `json:"up"`
+	Down               int     `json:"down"`
+	Unreachable        int     `json:"unreachable"`
+	Pending            int     `json:"pending"`
+	Handled            int     `json:"handled"`
+	Unhandled          int     `json:"unhandled"`
+	PercentUp          float32 `json:"percent_up"`
+	PercentDown        float32 `json:"percent_down"`
+	PercentUnreachable float32 `json:"percent_unreachable"`
+	PercentHandled     float32 `json:"percent_handled"`
+}
+
+type apiServiceCounts struct {
+	Ok              int     `json:"ok"`
+	Warning         int     `json:"warning"`
+	Critical        int     `json:"critical"`
+	Unknown         int     `json:"unknown"`
+	Unreachable     int     `json:"unreachable"`
+	Pending         int     `json:"pending"`
+	Handled         int     `json:"handled"`
+	Unhandled       int     `json:"unhandled"`
+	PercentOk       float32 `json:"percent_ok"`
+	PercentWarning  float32 `json:"percent_warning"`
+	PercentCritical float32 `json:"percent_critical"`
+	PercentUnknown  float32 `json:"percent_unknown"`
+	PercentHandled  float32 `json:"percent_handled"`
+}
+
+type apiProblemCounts struct {
+	Hosts    int `json:"hosts"`
+	Services int `json:"services"`
+	Cluster  int `json:"cluster"`
+	Stale    int `json:"stale"`
+	Features int `json:"features"`
+	New      int `json:"new"`
+}
+
+func writeAPIResponse(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		fmt.Fprintf(logOutput, "Error writing API response: %v\n", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, code string, message string) {
+	writeAPIResponse(w, status, apiErrorResponse{Error: apiError{Code: code, Message: message}})
+}
+
+func serveAPIv2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET is supported")
+		return
+	}
+
+	switch strings.TrimPrefix(r.URL.Path, "/api/v2") {
+	case "/openapi.json":
+		serveAPISpec(w)
+	case "/hosts":
+		if page, ok := apiSnapshot(w, r); ok {
+			writeAPIList(w, r, apiHosts(page, r.URL.Query()))
+		}
+	case "/services":
+		if page, ok := apiSnapshot(w, r); ok {
+			writeAPIList(w, r, apiServices(page, r.URL.Query()))
+		}
+	case "/summary":
+		if page, ok := apiSnapshot(w, r); ok {
+			summary, err := projectFields(apiSummaryOf(page), splitList(r.URL.Query().Get("fields")))
+			if err != nil {
+				writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+				return
+			}
+			writeJSONSnapshot(w, r, summary)
+		}
+	default:
+		writeAPIError(w, http.StatusNotFound, "not_found", "Unknown resource: "+r.URL.Path)
+	}
+}
+
+// writeAPIList writes the page of items selected by limit and offset,
+// reduced to the properties selected by fields.
+func writeAPIList[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	params, err := parseListParams(r.URL.Query())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	page, next := paginate(items, params)
+	list := apiList[interface{}]{
+		Items:      make([]interface{}, 0, len(page)),
+		Total:      len(items),
+		Offset:     params.Offset,
+		Limit:      params.Limit,
+		NextOffset: next,
+	}
+	for _, item := range page {
+		projected, err := projectFields(item, params.Fields)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+			return
+		}
+		list.Items = append(list.Items, projected)
+	}
+
+	writeJSONSnapshot(w, r, list)
+}
+
+func serveAPISpec(w http.ResponseWriter) {
+	spec, err := embeddedFiles.ReadFile("openapi.json")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(spec)
+}
+
+// apiSnapshot validates the query parameters and builds the dashboard data
+// for an API request. It writes an error response and returns false if that fails.
+func apiSnapshot(w http.ResponseWriter, r *http.Request) (PageVariables, bool) {
+	query := r.URL.Query()
+	if err := validateAPIQuery(query); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return PageVariables{}, false
+	}
+
+	page := buildPageVariables(completeRequest(r), nil)
+	if page.Error != nil {
+		writeAPIError(w, http.StatusBadGateway, "icinga_unavailable", page.Error.Error())
+		return page, false
+	}
+	return page, true
+}
+
+// validateAPIQuery rejects filter parameters the dashboard would silently ignore.
+func validateAPIQuery(query url.Values) error {
+	for _, name := range []string{"minState", "maxState", "minStateType", "groupThreshold"} {
+		if value := query.Get(name); value != "" {
+			if _, err := strconv.Atoi(value); err != nil {
+				return fmt.Errorf("%s must be an integer, got %q", name, value)
+			}
+		}
+	}
+	if value := query.Get("groupBy"); value != "" && !isValidGroupBy(value) {
+		return fmt.Errorf("groupBy has an invalid value: %s", value)
+	}
+	if value := query.Get("sort"); value != "" && !isValidSortOrder(value) {
+		return fmt.Errorf("sort has an invalid value: %s", value)
+	}
+	if value := query.Get("view"); value != "" {
+		if _, ok := views.Views[value]; !ok {
+			return fmt.Errorf("unknown view: %s", value)
+		}
+	}
+	if _, err := parseListParams(query); err != nil {
+		return err
+	}
+	return nil
+}
+
+// matchesName reports whether filter is empty or a case-insensitive part of one of the names.
+func matchesName(filter string, names ...string) bool {
+	if filter == "" {
+		return true
+	}
+	for _, name := range names {
+		if strings.Contains(strings.ToLower(name), strings.ToLower(filter)) {
+			return true
+		}
+	}
+	return false
+}
+
+func apiHosts(page PageVariables, query url.Values) []apiHost {
+	hosts := make([]apiHost, 0, len(page.HostRecords))
+	for _, record := range page.HostRecords {
+		if !matchesName(query.Get("host"), record.Name) {
+			continue
+		}
+		unreachable := record.UnreachableHosts
+		if unreachable == nil {
+			unreachable = []string{}
+		}
+		hosts = append(hosts, apiHost{
+			Name:             record.Name,
+			State:            record.State,
+			StateName:        hostStateNumToString(record.State),
+			StateType:        record.StateType,
+			StateTypeName:    stateTypeNumToString(record.StateType),
+			UnreachableHosts: unreachable,
+			LastStateChange:  optionalRFC3339(record.LastStateChange),
+			Priority:         record.Priority,
+			IsNew:            record.IsNew,
+		})
+	}
+	return hosts
+}
+
+func apiServices(page PageVariables, query url.Values) []apiService {
+	services := make([]apiService, 0, len(page.ServiceRecords))
+	for _, record := range page.ServiceRecords {
+		if !matchesName(query.Get("host"), record.AggregatedHosts...) || !matchesName(query.Get("service"), record.AggregatedServices...) {
+			continue
+		}
+		services = append(services, apiService{
+			Name:            record.Name,
+			Group:           record.GroupName,
+			State:           record.State,
+			StateName:       stateNumToString(record.State),
+			StateType:       record.StateType,
+			StateTypeName:   stateTypeNumToString(record.StateType),
+			Hosts:           record.AggregatedHosts,
+			Services:        record.AggregatedServices,
+			IsAggregated:    record.IsAggregated,
+			Since:           optionalRFC3339(record.Since),
+			LastStateChange: optionalRFC3339(record.LastStateChange),
+			Priority:        record.Priority,
+			Weight:          record.Weight,
+			IsNew:           record.IsNew,
+		})
+	}
+	return services
+}
+
+func apiSummaryOf(page PageVariables) apiSummary {
+	summary := apiSummary{
+		Time:                 page.TimeRFC3339,
+		Timezone:             page.Timezone,
+		NotificationsEnabled: !page.NotificationsDisabled,
+		DisabledFeatures:     page.DisabledFeatures,
+		Problems: apiProblemCounts{
+			Hosts:    len(page.HostRecords),
+			Services: len(page.ServiceRecords),
+			Cluster:  len(page.ClusterRecords),
+			Stale:    page.StaleCount,
+			Features: len(page.DisabledFeatures),
+			New:      page.NewProblems,
+		},
+	}
+	if status := page.CIBStatus; status != nil {
+		summary.Hosts = apiHostCounts{
+			Up:                 status.NumHostsUp,
+			Down:               status.NumHostsDown,
+			Unreachable:        status.NumHostsUnreachable,
+			Pending:            status.NumHostsPending,
+			Handled:            status.NumHostsHandled,
+			Unhandled:          status.NumHostsUnhandled(),
+			PercentUp:          status.PercentHostsUp(),
+			PercentDown:        status.PercentHostsDown(),
+			PercentUnreachable: status.PercentHostsUnreachable(),
+			PercentHandled:     status.PercentHostsHandled(),
+		}
+		summary.Services = apiServiceCounts{
+			Ok:              status.NumServicesOk,
+			Warning:         status.NumServicesWarning,
+			Critical:        status.NumServicesCritical,
+			Unknown:         status.NumServicesUnknown,
+			Unreachable:     status.NumServicesUnreachable,
+			Pending:         status.NumServicesPending,
+			Handled:         status.NumServicesHandled,
+			Unhandled:       status.NumServicesUnhandled(),
+			PercentOk:       status.PercentServicesOk(),
+			PercentWarning:  status.PercentServicesWarning(),
+			PercentCritical: status.PercentServicesCritical(),
+			PercentUnknown:  status.PercentServicesUnknown(),
+			PercentHandled:  status.PercentServicesHandled(),
+		}
+	}
+	if info := page.Icinga; info != nil {
+		summary.Icinga = apiIcinga{
+			Version:           info.Version,
+			NodeName:          info.NodeName,
+			Environment:       info.Environment,
+			PID:               info.PID,
+			ProgramStart:      optionalRFC3339(info.ProgramStart),
+			Uptime:            info.Uptime,
+			RecentlyRestarted: info.RecentlyRestarted,
+		}
+	}
+	return summary
+}
+
+func optionalRFC3339(t timestamp) *string {
+	formatted := rfc3339(t)
+	if formatted == "" {
+		return nil
+	}
+	return &formatted
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+// openAPISpec is the part of an OpenAPI document needed to check responses against it.
+type openAPISpec struct {
+	raw map[string]interface{}
+}
+
+func loadOpenAPISpec(t *testing.T) openAPISpec {
+	t.Helper()
+	data, err := embeddedFiles.ReadFile("openapi.json")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("expected valid JSON spec, got %v", err)
+	}
+	return openAPISpec{raw}
+}
+
+// resolve follows a local reference like "#/components/schemas/Host".
+func (s openAPISpec) resolve(node map[string]interface{}) map[string]interface{} {
+	ref, ok := node["$ref"].(string)
+	if !ok {
+		return node
+	}
+	var current interface{} = s.raw
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		current = current.(map[string]interface{})[part]
+	}
+	return s.resolve(current.(map[string]interface{}))
+}
+
+func (s openAPISpec) responseSchema(path string, status int) (map[string]interface{}, error) {
+	operation, ok := s.raw["paths"].(map[string]interface{})[path].(map[string]interface{})["get"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no GET operation for %s", path)
+	}
+	response, ok := operation["responses"].(map[string]interface{})[strconv.Itoa(status)].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("status %d is not documented for %s", status, path)
+	}
+	content := s.resolve(response)["content"].(map[string]interface{})["application/json"].(map[string]interface{})
+	return s.resolve(content["schema"].(map[string]interface{})), nil
+}
+
+// validate checks value against a schema. Undocumented properties are
+// reported too, so the spec can't fall behind the implementation.
+func (s openAPISpec) validate(schema map[string]interface{}, value interface{}, path string) error {
+	schema = s.resolve(schema)
+	if value == nil {
+		if schema["nullable"] == true {
+			return nil
+		}
+		return fmt.Errorf("%s must not be null", path)
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be an object", path)
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := object[name.(string)]; !ok {
+					return fmt.Errorf("%s misses required property %s", path, name)
+				}
+			}
+		}
+		for name, property := range object {
+			propertySchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s has undocumented property %s", path, name)
+			}
+			if err := s.validate(propertySchema, property, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be an array", path)
+		}
+		for i, item := range items {
+			if err := s.validate(schema["items"].(map[string]interface{}), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a string", path)
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, text); err != nil {
+				return fmt.Errorf("%s must be a date-time: %v", path, err)
+			}
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != float64(int64(number)) {
+			return fmt.Errorf("%s must be an integer", path)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s must be a number", path)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s must be a boolean", path)
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		for _, allowed := range enum {
+			if allowed == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s has a value %v not in %v", path, value, enum)
+	}
+	return nil
+}
+
+func withAPITestClient(t *testing.T, stub stubDashboardClient) {
+	originalClient := client
+	originalNow := now
+	originalGrouping := defaultGrouping
+	originalSortOrder := defaultSortOrder
+	originalMinState := defaultMinState
+	originalMaxState := defaultMaxState
+	t.Cleanup(func() {
+		client = originalClient
+		now = originalNow
+		defaultGrouping = originalGrouping
+		defaultSortOrder = originalSortOrder
+		defaultMinState = originalMinState
+		defaultMaxState = originalMaxState
+	})
+
+	client = stub
+	now = func() time.Time {
+		return time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
+	}
+	defaultGrouping = serviceGrouping{By: groupByService, Threshold: 2}
+	defaultSortOrder = sortBySeverity
+	defaultMinState = 1
+	defaultMaxState = 3
+}
+
+func apiTestClient() stubDashboardClient {
+	changed := time.Date(2026, time.March, 11, 7, 0, 0, 0, time.UTC)
+	return stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{EnableNotifications: true},
+		cibStatus: &icinga2apiclient.CIBStatus{NumHostsUp: 9, NumHostsDown: 1, NumServicesOk: 40, NumServicesCritical: 3},
+		hosts: []icinga2apiclient.Host{
+			{Name: "core-sw-1", State: 1, StateType: 1, LastStateChange: changed},
+			{Name: "web-1", State: 1, StateType: 1, Unreachable: true, LastStateChange: changed.Add(5 * time.Minute)},
+		},
+		deps: []icinga2apiclient.Dependency{
+			{ChildHostName: "web-1", ParentHostName: "core-sw-1"},
+		},
+		services: []icinga2apiclient.Service{
+			{HostName: "web-1", ServiceName: "http", State: 2, StateType: 1, LastStateChange: changed},
+			{HostName: "web-2", ServiceName: "http", State: 2, StateType: 1, LastStateChange: changed},
+			{HostName: "db-1", ServiceName: "disk", State: 1, StateType: 0},
+		},
+	}
+}
+
+func TestAPIv2MatchesOpenAPISpec(t *testing.T) {
+	spec := loadOpenAPISpec(t)
+
+	tests := []struct {
+		name     string
+		stub     stubDashboardClient
+		url      string
+		specPath string
+		status   int
+	}{
+		{"hosts", apiTestClient(), "/api/v2/hosts", "/api/v2/hosts", http.StatusOK},
+		{"services", apiTestClient(), "/api/v2/services", "/api/v2/services", http.StatusOK},
+		{"ungrouped services", apiTestClient(), "/api/v2/services?groupBy=none", "/api/v2/services", http.StatusOK},
+		{"summary", apiTestClient(), "/api/v2/summary", "/api/v2/summary", http.StatusOK},
+		{"no problems", stubDashboardClient{
+			appStatus: &icinga2apiclient.IcingaApplication{},
+			cibStatus: &icinga2apiclient.CIBStatus{},
+		}, "/api/v2/services", "/api/v2/services", http.StatusOK},
+		{"invalid parameter", apiTestClient(), "/api/v2/services?minState=critical", "/api/v2/services", http.StatusBadRequest},
+		{"icinga unavailable", stubDashboardClient{
+			appErr:    errors.New("connection refused"),
+			cibStatus: &icinga2apiclient.CIBStatus{},
+		}, "/api/v2/summary", "/api/v2/summary", http.StatusBadGateway},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withAPITestClient(t, test.stub)
+
+			rec := httptest.NewRecorder()
+			serveAPIv2(rec, httptest.NewRequest(http.MethodGet, test.url, nil))
+
+			if rec.Code != test.status {
+				t.Fatalf("expected status %d, got %d: %s", test.status, rec.Code, rec.Body.String())
+			}
+			if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("expected JSON content type, got %q", contentType)
+			}
+			schema, err := spec.responseSchema(test.specPath, test.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var body interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("expected JSON body, got %v", err)
+			}
+			if err := spec.validate(schema, body, "response"); err != nil {
+				t.Errorf("response doesn't match the spec: %v\n%s", err, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIv2ServesSpec(t *testing.T) {
+	rec := httptest.NewRecorder()
+	serveAPIv2(rec, httptest.NewRequest(http.MethodGet, "/api/v2/openapi.json", nil))
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"openapi": "3.0.3"`) {
+		t.Errorf("expected OpenAPI document, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAPIv2Errors(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		status int
+		code   string
+	}{
+		{http.MethodGet, "/api/v2/unknown", http.StatusNotFound, "not_found"},
+		{http.MethodPost, "/api/v2/hosts", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{http.MethodGet, "/api/v2/services?sort=random", http.StatusBadRequest, "invalid_parameter"},
+		{http.MethodGet, "/api/v2/hosts?view=missing", http.StatusBadRequest, "invalid_parameter"},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		serveAPIv2(rec, httptest.NewRequest(test.method, test.url, nil))
+
+		var response apiErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s %s: expected error object, got %v", test.method, test.url, err)
+		}
+		if rec.Code != test.status || response.Error.Code != test.code {
+			t.Errorf("%s %s: expected %d %s, got %d %+v", test.method, test.url, test.status, test.code, rec.Code, response.Error)
+		}
+	}
+}
+
+func TestAPIv2FiltersByName(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+
+	rec := httptest.NewRecorder()
+	serveAPIv2(rec, httptest.NewRequest(http.MethodGet, "/api/v2/services?groupBy=none&host=WEB", nil))
+
+	var services apiList[apiService]
+	if err := json.Unmarshal(rec.Body.Bytes(), &services); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if services.Total != 2 {
+		t.Fatalf("expected 2 services of web hosts, got %+v", services)
+	}
+	for _, service := range services.Items {
+		if service.Name != "http" || service.StateName != "Critical" {
+			t.Errorf("unexpected service %+v", service)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	serveAPIv2(rec, httptest.NewRequest(http.MethodGet, "/api/v2/hosts", nil))
+
+	var hosts apiList[apiHost]
+	if err := json.Unmarshal(rec.Body.Bytes(), &hosts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if hosts.Total != 1 || hosts.Items[0].Name != "core-sw-1" || hosts.Items[0].StateName != "Down" {
+		t.Fatalf("expected core-sw-1 as only root host, got %+v", hosts)
+	}
+	if len(hosts.Items[0].UnreachableHosts) != 1 || *hosts.Items[0].LastStateChange != "2026-03-11T07:00:00Z" {
+		t.Errorf("unexpected host %+v", hosts.Items[0])
+	}
+}
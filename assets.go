@@ -0,0 +1,132 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Templates and static assets are compiled into the binary, so it can be
+// started from any directory.
+//
+//go:embed *.html favicon.ico openapi.json assets themes locales
+var embeddedFiles embed.FS
+
+// layeredFS looks up files in each layer in turn and returns the first match.
+// It allows overriding single embedded files with files from a directory.
+type layeredFS []fs.FS
+
+func (l layeredFS) Open(name string) (fs.File, error) {
+	var firstErr error
+	for _, layer := range l {
+		file, err := layer.Open(name)
+		if err == nil {
+			return file, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return nil, firstErr
+}
+
+// ReadDir merges the directory listings of all layers, so fs.Glob finds
+// files no matter which layer they are in.
+func (l layeredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries := make(map[string]fs.DirEntry)
+	found := false
+	for _, layer := range l {
+		layerEntries, err := fs.ReadDir(layer, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, entry := range layerEntries {
+			if _, exists := entries[entry.Name()]; !exists {
+				entries[entry.Name()] = entry
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	var result []fs.DirEntry
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+	return result, nil
+}
+
+// templateLoader parses the HTML templates once and hands out the parsed
+// result. With reload enabled it parses them again whenever one of them
+// changed on disk, which is handy while working on the templates.
+type templateLoader struct {
+	fsys   fs.FS
+	reload bool
+
+	mu      sync.Mutex
+	tmpl    *template.Template
+	modTime time.Time
+}
+
+func newTemplateLoader(fsys fs.FS, reload bool) *templateLoader {
+	return &templateLoader{fsys: fsys, reload: reload}
+}
+
+func (l *templateLoader) Get() (*template.Template, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.tmpl != nil && !l.reload {
+		return l.tmpl, nil
+	}
+
+	modTime, err := latestModTime(l.fsys, "*.html")
+	if err != nil {
+		return nil, err
+	}
+	if l.tmpl != nil && !modTime.After(l.modTime) {
+		return l.tmpl, nil
+	}
+
+	tmpl, err := template.ParseFS(l.fsys, "*.html")
+	if err != nil {
+		return nil, err
+	}
+	l.tmpl = tmpl
+	l.modTime = modTime
+
+	return tmpl, nil
+}
+
+func latestModTime(fsys fs.FS, pattern string) (time.Time, error) {
+	var latest time.Time
+
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return latest, err
+	}
+	for _, name := range names {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return latest, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestEmbeddedFiles(t *testing.T) {
+	for _, name := range []string{"index.html", "favicon.ico", "assets/style.css"} {
+		if _, err := fs.Stat(embeddedFiles, name); err != nil {
+			t.Errorf("expected %s to be embedded: %v", name, err)
+		}
+	}
+}
+
+func TestLayeredFS(t *testing.T) {
+	override := fstest.MapFS{
+		"assets/style.css": {Data: []byte("custom")},
+	}
+	base := fstest.MapFS{
+		"index.html":       {Data: []byte("index")},
+		"assets/style.css": {Data: []byte("default")},
+	}
+	files := layeredFS{override, base}
+
+	data, err := fs.ReadFile(files, "assets/style.css")
+	if err != nil || string(data) != "custom" {
+		t.Errorf("expected overridden style.css, got %q (%v)", data, err)
+	}
+	data, err = fs.ReadFile(files, "index.html")
+	if err != nil || string(data) != "index" {
+		t.Errorf("expected index.html from base layer, got %q (%v)", data, err)
+	}
+	if _, err := fs.ReadFile(files, "missing.html"); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+
+	names, err := fs.Glob(files, "*.html")
+	if err != nil || len(names) != 1 || names[0] != "index.html" {
+		t.Errorf("expected glob to find index.html, got %v (%v)", names, err)
+	}
+}
+
+func TestTemplateLoader(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html": {Data: []byte("first"), ModTime: time.Unix(100, 0)},
+	}
+
+	loader := newTemplateLoader(files, false)
+	if got := executeTemplate(t, loader); got != "first" {
+		t.Fatalf("expected first, got %q", got)
+	}
+	files["index.html"] = &fstest.MapFile{Data: []byte("second"), ModTime: time.Unix(200, 0)}
+	if got := executeTemplate(t, loader); got != "first" {
+		t.Errorf("expected templates to be parsed only once without reload, got %q", got)
+	}
+
+	reloading := newTemplateLoader(files, true)
+	if got := executeTemplate(t, reloading); got != "second" {
+		t.Fatalf("expected second, got %q", got)
+	}
+	files["index.html"] = &fstest.MapFile{Data: []byte("third"), ModTime: time.Unix(300, 0)}
+	if got := executeTemplate(t, reloading); got != "third" {
+		t.Errorf("expected changed template to be reloaded, got %q", got)
+	}
+}
+
+func executeTemplate(t *testing.T, loader *templateLoader) string {
+	t.Helper()
+	tmpl, err := loader.Get()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "index.html", nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+// cliFilters are the query parameters of the web UI the command-line client passes on.
+var cliFilters = []string{"view", "minState", "maxState", "minStateType", "hostGroup", "groupBy", "groupThreshold", "sort", "host", "service"}
+
+// ANSI colors of the states
+const (
+	ansiReset   = "\033[0m"
+	ansiRed     = "\033[31m"
+	ansiYellow  = "\033[33m"
+	ansiMagenta = "\033[35m"
+	ansiBold    = "\033[1m"
+	// Moves the cursor home and clears the screen
+	ansiClear = "\033[H\033[2J"
+)
+
+type cliOptions struct {
+	URL      string
+	JSON     bool
+	Watch    bool
+	Interval time.Duration
+	Color    bool
+	Query    url.Values
+}
+
+// cliSnapshot is what the command-line client fetches from the JSON API.
+type cliSnapshot struct {
+	Summary  apiSummary   `json:"summary"`
+	Hosts    []apiHost    `json:"hosts"`
+	Services []apiService `json:"services"`
+}
+
+// runCLI implements "icinga-dashboard cli", a terminal client of the JSON API
+// of a running dashboard. It returns the exit code.
+func runCLI(args []string, stdout io.Writer, stderr io.Writer) int {
+	options, err := parseCLIFlags(args, stdout, stderr)
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	for {
+		snapshot, err := fetchCLISnapshot(client, options)
+		if options.Watch {
+			fmt.Fprint(stdout, ansiClear)
+		}
+		switch {
+		case err != nil:
+			fmt.Fprintf(stderr, "Error fetching problems from %s: %v\n", options.URL, err)
+			if !options.Watch {
+				return 1
+			}
+		case options.JSON:
+			encoder := json.NewEncoder(stdout)
+			encoder.SetIndent("", "  ")
+			encoder.Encode(snapshot)
+		default:
+			writeCLITable(stdout, snapshot, options.Color, now())
+		}
+
+		if !options.Watch {
+			return 0
+		}
+		time.Sleep(options.Interval)
+	}
+}
+
+func parseCLIFlags(args []string, stdout io.Writer, stderr io.Writer) (cliOptions, error) {
+	options := cliOptions{Query: url.Values{}}
+
+	flags := flag.NewFlagSet("icinga-dashboard cli", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	defaultURL := os.Getenv("ICINGA_DASHBOARD_URL")
+	if defaultURL == "" {
+		defaultURL = "http://localhost:8080"
+	}
+	flags.StringVar(&options.URL, "url", defaultURL, "URL of the dashboard, defaults to $ICINGA_DASHBOARD_URL")
+	flags.BoolVar(&options.JSON, "json", false, "Print the problems as JSON")
+	flags.BoolVar(&options.Watch, "watch", false, "Refresh the problems until interrupted")
+	flags.DurationVar(&options.Interval, "interval", refreshIntervalSeconds*time.Second, "Time between refreshes in watch mode")
+	color := flags.String("color", "auto", "Color the states: auto, always or never")
+	filters := make(map[string]*string)
+	for _, name := range cliFilters {
+		filters[name] = flags.String(name, "", "Same as the query parameter \""+name+"\" of the dashboard")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return options, err
+	}
+	for name, value := range filters {
+		if *value != "" {
+			options.Query.Set(name, *value)
+		}
+	}
+
+	switch *color {
+	case "always":
+		options.Color = true
+	case "never":
+		options.Color = false
+	case "auto":
+		options.Color = isTerminal(stdout) && os.Getenv("NO_COLOR") == ""
+	default:
+		fmt.Fprintf(stderr, "invalid value %q for -color: must be auto, always or never\n", *color)
+		return options, fmt.Errorf("invalid color: %s", *color)
+	}
+	return options, nil
+}
+
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func fetchCLISnapshot(client *http.Client, options cliOptions) (cliSnapshot, error) {
+	var snapshot cliSnapshot
+	if err := fetchAPI(client, options.URL, "/summary", options.Query, &snapshot.Summary); err != nil {
+		return snapshot, err
+	}
+
+	var hosts apiList[apiHost]
+	if err := fetchAPI(client, options.URL, "/hosts", options.Query, &hosts); err != nil {
+		return snapshot, err
+	}
+	var services apiList[apiService]
+	if err := fetchAPI(client, options.URL, "/services", options.Query, &services); err != nil {
+		return snapshot, err
+	}
+	snapshot.Hosts, snapshot.Services = hosts.Items, services.Items
+	return snapshot, nil
+}
+
+// fetchAPI decodes a resource of the v2 JSON API into target.
+func fetchAPI(client *http.Client, baseURL string, path string, query url.Values, target interface{}) error {
+	resp, err := client.Get(strings.TrimRight(baseURL, "/") + "/api/v2" + path + "?" + query.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr apiErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Error.Message == "" {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return fmt.Errorf("%s: %s", apiErr.Error.Code, apiErr.Error.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// writeCLITable prints the summary and one line per problem, colored by state.
+func writeCLITable(w io.Writer, snapshot cliSnapshot, color bool, currentTime time.Time) {
+	summary := snapshot.Summary
+	fmt.Fprintf(w, "Hosts: %d up, %d down (%.1f%% up)   Services: %d OK, %d warning, %d critical, %d unknown (%.1f%% OK)   %s\n",
+		summary.Hosts.Up, summary.Hosts.Down, icinga2apiclient.RoundPercent(summary.Hosts.PercentUp),
+		summary.Services.Ok, summary.Services.Warning, summary.Services.Critical, summary.Services.Unknown, icinga2apiclient.RoundPercent(summary.Services.PercentOk),
+		currentTime.Format(time.DateTime))
+	for _, feature := range summary.DisabledFeatures {
+		fmt.Fprintln(w, colorize(featureNames[feature]+" disabled globally!", ansiRed, color))
+	}
+	fmt.Fprintln(w)
+
+	if len(snapshot.Hosts) == 0 && len(snapshot.Services) == 0 {
+		fmt.Fprintln(w, colorize("No problems", ansiBold, color))
+		return
+	}
+
+	var table bytes.Buffer
+	writer := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "STATE\tHOST\tSERVICE\tSINCE")
+	var colors []string
+	for _, host := range snapshot.Hosts {
+		state := strings.ToUpper(host.StateName)
+		if len(host.UnreachableHosts) > 0 {
+			state += fmt.Sprintf(" (%d unreachable)", len(host.UnreachableHosts))
+		}
+		fmt.Fprintf(writer, "%s\t%s\t-\t%s\n", state, host.Name, cliSince(host.LastStateChange, currentTime))
+		colors = append(colors, ansiRed)
+	}
+	for _, service := range snapshot.Services {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", strings.ToUpper(service.StateName), cliList(service.Hosts, "hosts"), cliList(service.Services, "services"), cliSince(service.Since, currentTime))
+		colors = append(colors, stateColor(service.State))
+	}
+	writer.Flush()
+
+	// Colors are added after aligning the columns, escape sequences would confuse the tabwriter
+	lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	fmt.Fprintln(w, colorize(lines[0], ansiBold, color))
+	for i, line := range lines[1:] {
+		fmt.Fprintln(w, colorize(line, colors[i], color))
+	}
+}
+
+func stateColor(state int) string {
+	switch state {
+	case 1:
+		return ansiYellow
+	case 2:
+		return ansiRed
+	default:
+		return ansiMagenta
+	}
+}
+
+func colorize(text string, color string, enabled bool) string {
+	if !enabled {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+// cliList shortens long lists of aggregated hosts or services to their count.
+func cliList(names []string, noun string) string {
+	if len(names) > 3 {
+		return fmt.Sprintf("%d %s", len(names), noun)
+	}
+	return strings.Join(names, ",")
+}
+
+func cliSince(since *string, currentTime time.Time) string {
+	if since == nil {
+		return "-"
+	}
+	parsed, err := time.Parse(time.RFC3339, *since)
+	if err != nil {
+		return "-"
+	}
+	return formatDuration(currentTime.Sub(parsed))
+}
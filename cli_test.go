@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunCLI(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+	server := httptest.NewServer(http.HandlerFunc(serveAPIv2))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := runCLI([]string{"-url", server.URL, "-color", "never"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	want := `Hosts: 9 up, 1 down (90.0% up)   Services: 40 OK, 0 warning, 3 critical, 0 unknown (93.0% OK)   2026-03-11 08:09:10
+Host checks disabled globally!
+Service checks disabled globally!
+Event handlers disabled globally!
+Flap detection disabled globally!
+Performance data disabled globally!
+
+STATE                 HOST         SERVICE  SINCE
+DOWN (1 unreachable)  core-sw-1    -        1h 9m
+CRITICAL              web-1,web-2  http     1h 9m
+WARNING               db-1         disk     -
+`
+	if stdout.String() != want {
+		t.Errorf("unexpected output\n got:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+}
+
+func TestRunCLIWithFiltersAndJSON(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		serveAPIv2(w, r)
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := runCLI([]string{"-url", server.URL, "-json", "-minState", "2", "-service", "http"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	for _, query := range queries {
+		if query != "minState=2&service=http" {
+			t.Errorf("expected the filters to be passed on, got %s", query)
+		}
+	}
+
+	var snapshot cliSnapshot
+	if err := json.Unmarshal(stdout.Bytes(), &snapshot); err != nil {
+		t.Fatalf("expected JSON output, got %v: %s", err, stdout.String())
+	}
+	if len(snapshot.Services) != 1 || snapshot.Services[0].Services[0] != "http" || snapshot.Summary.Hosts.Up != 9 {
+		t.Errorf("unexpected snapshot %+v", snapshot)
+	}
+}
+
+func TestRunCLIColors(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+	server := httptest.NewServer(http.HandlerFunc(serveAPIv2))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	runCLI([]string{"-url", server.URL, "-color", "always"}, &stdout, &stderr)
+	for _, expected := range []string{ansiRed + "CRITICAL", ansiYellow + "WARNING", ansiRed + "DOWN"} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("expected %q in output, got %q", expected, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	runCLI([]string{"-url", server.URL}, &stdout, &stderr)
+	if strings.Contains(stdout.String(), "\033[") {
+		t.Errorf("expected no colors when not writing to a terminal, got %q", stdout.String())
+	}
+}
+
+func TestRunCLIErrors(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+	server := httptest.NewServer(http.HandlerFunc(serveAPIv2))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	if code := runCLI([]string{"-url", server.URL, "-minState", "high"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "invalid_parameter") {
+		t.Errorf("expected the API error, got %s", stderr.String())
+	}
+
+	stderr.Reset()
+	if code := runCLI([]string{"-color", "rainbow"}, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 for an invalid flag, got %d", code)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	// The Docker image doesn't ship a timezone database
+	_ "time/tzdata"
+)
+
+// clockSettings control how the current time is shown in the info bar.
+type clockSettings struct {
+	// Timezone of the main clock, nil means the server's local time
+	Location *time.Location
+	// Additional clocks shown next to the main clock
+	Clocks   []clockConfig
+	ShowDate bool
+}
+
+type clockConfig struct {
+	Label    string
+	Location *time.Location
+}
+
+// clock is a rendered additional clock.
+type clock struct {
+	Label      string `json:"label"`
+	Timezone   string `json:"timezone"`
+	TimeString string `json:"time_string"`
+}
+
+// parseTimezone loads an IANA timezone like "Europe/Berlin". An empty value
+// returns nil, which keeps times in the server's local zone.
+func parseTimezone(value string) (*time.Location, error) {
+	if value == "" {
+		return nil, nil
+	}
+	return time.LoadLocation(value)
+}
+
+// parseClocks parses a comma separated list of timezones. Each timezone may
+// carry a label, e.g. "UTC,NYC=America/New_York". Without a label the
+// timezone name is shown.
+func parseClocks(value string) ([]clockConfig, error) {
+	var clocks []clockConfig
+	for _, entry := range splitList(value) {
+		label, zone, found := strings.Cut(entry, "=")
+		if !found {
+			zone = label
+		}
+		location, err := time.LoadLocation(strings.TrimSpace(zone))
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", zone, err)
+		}
+		clocks = append(clocks, clockConfig{Label: strings.TrimSpace(label), Location: location})
+	}
+	return clocks, nil
+}
+
+// renderClocks formats currentTime for every configured clock.
+func renderClocks(clocks []clockConfig, currentTime time.Time, format string) []clock {
+	var rendered []clock
+	for _, c := range clocks {
+		rendered = append(rendered, clock{
+			Label:      c.Label,
+			Timezone:   c.Location.String(),
+			TimeString: currentTime.In(c.Location).Format(format),
+		})
+	}
+	return rendered
+}
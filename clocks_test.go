@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func TestParseClocks(t *testing.T) {
+	clocks, err := parseClocks("UTC, NYC=America/New_York")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(clocks) != 2 {
+		t.Fatalf("expected 2 clocks, got %d", len(clocks))
+	}
+	if clocks[0].Label != "UTC" || clocks[0].Location.String() != "UTC" {
+		t.Errorf("unexpected first clock %+v", clocks[0])
+	}
+	if clocks[1].Label != "NYC" || clocks[1].Location.String() != "America/New_York" {
+		t.Errorf("unexpected second clock %+v", clocks[1])
+	}
+
+	if _, err := parseClocks("Mars/Olympus_Mons"); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+	if clocks, err := parseClocks(""); err != nil || clocks != nil {
+		t.Errorf("expected no clocks, got %v, %v", clocks, err)
+	}
+}
+
+func TestRenderClocks(t *testing.T) {
+	clocks, _ := parseClocks("UTC,Tokyo=Asia/Tokyo")
+	currentTime := time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
+
+	rendered := renderClocks(clocks, currentTime, "15:04")
+	if rendered[0].TimeString != "08:09" || rendered[1].TimeString != "17:09" {
+		t.Errorf("unexpected clocks %+v", rendered)
+	}
+	if rendered[1].Label != "Tokyo" || rendered[1].Timezone != "Asia/Tokyo" {
+		t.Errorf("unexpected clock %+v", rendered[1])
+	}
+}
+
+func TestBuildPageVariablesWithTimezone(t *testing.T) {
+	originalClient := client
+	originalClocks := defaultClocks
+	originalNow := now
+	defer func() {
+		client = originalClient
+		defaultClocks = originalClocks
+		now = originalNow
+	}()
+
+	client = stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{EnableNotifications: true},
+		cibStatus: &icinga2apiclient.CIBStatus{},
+	}
+	defaultClocks = clockSettings{}
+	now = func() time.Time {
+		return time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?tz=Europe/Berlin&clocks=UTC&date=1&lang=de", nil)
+	page := buildPageVariables(req, nil)
+
+	if page.TimeString != "09:09:10" {
+		t.Errorf("expected time in Berlin, got %s", page.TimeString)
+	}
+	if page.DateString != "11.03.2026" {
+		t.Errorf("expected German date, got %s", page.DateString)
+	}
+	if page.TimeRFC3339 != "2026-03-11T09:09:10+01:00" {
+		t.Errorf("expected RFC3339 time with offset, got %s", page.TimeRFC3339)
+	}
+	if page.Timezone != "Europe/Berlin" {
+		t.Errorf("expected timezone Europe/Berlin, got %s", page.Timezone)
+	}
+	if len(page.Clocks) != 1 || page.Clocks[0].TimeString != "08:09:10" {
+		t.Errorf("expected an additional UTC clock, got %+v", page.Clocks)
+	}
+	if page.Time.Unix() != 1773216550 {
+		t.Errorf("expected unix timestamp to stay unchanged, got %d", page.Time.Unix())
+	}
+}
+
+func TestRecordsMarshalRFC3339Timestamps(t *testing.T) {
+	changed := timestamp{time.Date(2026, time.March, 11, 8, 0, 0, 0, time.UTC)}
+
+	data, err := json.Marshal(PageServiceListRecord{Name: "disk", Since: changed, LastStateChange: changed})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	body := string(data)
+	for _, expected := range []string{
+		`"name":"disk"`,
+		`"since":1773216000`,
+		`"since_rfc3339":"2026-03-11T08:00:00Z"`,
+		`"last_state_change_rfc3339":"2026-03-11T08:00:00Z"`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %s in %s", expected, body)
+		}
+	}
+
+	data, err = json.Marshal(PageHostListRecord{Name: "core-sw-1"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(string(data), `"last_state_change_rfc3339":""`) {
+		t.Errorf("expected empty RFC3339 time for unknown state change, got %s", data)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+// Kinds of cluster problems, shown above the host problems
+const (
+	clusterZoneDisconnected     = "zone_disconnected"
+	clusterEndpointDisconnected = "endpoint_disconnected"
+	clusterLogLag               = "log_lag"
+)
+
+type PageClusterRecord struct {
+	Kind string `json:"kind"`
+	// Zone or endpoint name
+	Name      string   `json:"name"`
+	Zone      string   `json:"zone"`
+	Endpoints []string `json:"endpoints"`
+	// Seconds the replay log lags behind, only for log_lag
+	LogLag int `json:"log_lag"`
+	// Last message received from the endpoints
+	Since timestamp `json:"since"`
+}
+
+// LogLagString returns the replay log lag like "2h 13m".
+func (r PageClusterRecord) LogLagString() string {
+	return formatDuration(time.Duration(r.LogLag) * time.Second)
+}
+
+// EndpointList returns the endpoints of a zone as a comma separated list.
+func (r PageClusterRecord) EndpointList() string {
+	return strings.Join(r.Endpoints, ", ")
+}
+
+// getClusterRecords fetches the cluster health. A node without cluster, or
+// an API user without permission for it, just shows no cluster problems.
+func getClusterRecords(client dashboardClient, logLagThreshold int) []PageClusterRecord {
+	status, err := client.GetAPIListenerStatus()
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error getting ApiListener status: %v\n", err)
+		return nil
+	}
+	endpoints, err := client.GetEndpoints()
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error getting endpoints: %v\n", err)
+	}
+	zones, err := client.GetZones()
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error getting zones: %v\n", err)
+	}
+	return buildClusterRecords(status, endpoints, zones, logLagThreshold)
+}
+
+// buildClusterRecords lists zones not connected, disconnected endpoints of
+// otherwise connected zones and zones whose replay log lags at least
+// logLagThreshold seconds behind. The ApiListener status decides what is
+// connected, endpoint and zone objects only add details.
+func buildClusterRecords(status *icinga2apiclient.APIListenerStatus, endpoints []icinga2apiclient.Endpoint, zones []icinga2apiclient.Zone, logLagThreshold int) []PageClusterRecord {
+	if status == nil {
+		return nil
+	}
+
+	lastMessages := make(map[string]time.Time)
+	for _, endpoint := range endpoints {
+		lastMessages[endpoint.Name] = endpoint.LastMessageReceived
+	}
+	globalZones := make(map[string]bool)
+	for _, zone := range zones {
+		globalZones[zone.Name] = zone.Global
+	}
+	lastMessage := func(names []string) timestamp {
+		var last time.Time
+		for _, name := range names {
+			if lastMessages[name].After(last) {
+				last = lastMessages[name]
+			}
+		}
+		return timestamp{last}
+	}
+
+	var zoneRecords, endpointRecords, lagRecords []PageClusterRecord
+	endpointZones := make(map[string]string)
+	disconnectedZones := make(map[string]bool)
+	for _, name := range slices.Sorted(maps.Keys(status.Zones)) {
+		zone := status.Zones[name]
+		// The local zone is always connected to itself
+		if globalZones[name] || len(zone.Endpoints) == 0 || slices.Contains(zone.Endpoints, status.Identity) {
+			continue
+		}
+		for _, endpoint := range zone.Endpoints {
+			endpointZones[endpoint] = name
+		}
+
+		if !zone.Connected {
+			disconnectedZones[name] = true
+			zoneRecords = append(zoneRecords, PageClusterRecord{
+				Kind:      clusterZoneDisconnected,
+				Name:      name,
+				Zone:      name,
+				Endpoints: zone.Endpoints,
+				Since:     lastMessage(zone.Endpoints),
+			})
+			continue
+		}
+		if logLagThreshold > 0 && zone.ClientLogLag >= float64(logLagThreshold) {
+			lagRecords = append(lagRecords, PageClusterRecord{
+				Kind:      clusterLogLag,
+				Name:      name,
+				Zone:      name,
+				Endpoints: zone.Endpoints,
+				LogLag:    int(zone.ClientLogLag),
+			})
+		}
+	}
+
+	for _, name := range slices.Sorted(slices.Values(status.NotConnEndpoints)) {
+		zone := endpointZones[name]
+		if name == status.Identity || disconnectedZones[zone] {
+			continue
+		}
+		endpointRecords = append(endpointRecords, PageClusterRecord{
+			Kind:      clusterEndpointDisconnected,
+			Name:      name,
+			Zone:      zone,
+			Endpoints: []string{name},
+			Since:     lastMessage([]string{name}),
+		})
+	}
+
+	return slices.Concat(zoneRecords, endpointRecords, lagRecords)
+}
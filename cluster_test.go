@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func testAPIListenerStatus() *icinga2apiclient.APIListenerStatus {
+	return &icinga2apiclient.APIListenerStatus{
+		Identity:         "master1",
+		NotConnEndpoints: []string{"agent1", "sat-b1", "sat-a2"},
+		Zones: map[string]icinga2apiclient.APIListenerZone{
+			"master":           {Connected: true, Endpoints: []string{"master1", "master2"}},
+			"global-templates": {Connected: false},
+			"satellite-a":      {Connected: true, Endpoints: []string{"sat-a1", "sat-a2"}, ClientLogLag: 600},
+			"satellite-b":      {Connected: false, Endpoints: []string{"sat-b1"}},
+			"agent1":           {Connected: false, Endpoints: []string{"agent1"}},
+			"satellite-c":      {Connected: true, Endpoints: []string{"sat-c1"}, ClientLogLag: 12},
+		},
+	}
+}
+
+func TestBuildClusterRecords(t *testing.T) {
+	lastMessage := time.Unix(1700000000, 0)
+	endpoints := []icinga2apiclient.Endpoint{
+		{Name: "sat-a2", LastMessageReceived: lastMessage},
+		{Name: "sat-b1", LastMessageReceived: lastMessage.Add(time.Minute)},
+	}
+	zones := []icinga2apiclient.Zone{
+		{Name: "global-templates", Global: true},
+	}
+
+	records := buildClusterRecords(testAPIListenerStatus(), endpoints, zones, 300)
+
+	// Disconnected zones first, then endpoints of connected zones, then lagging zones
+	want := []PageClusterRecord{
+		{Kind: clusterZoneDisconnected, Name: "agent1", Zone: "agent1", Endpoints: []string{"agent1"}},
+		{Kind: clusterZoneDisconnected, Name: "satellite-b", Zone: "satellite-b", Endpoints: []string{"sat-b1"}, Since: timestamp{lastMessage.Add(time.Minute)}},
+		{Kind: clusterEndpointDisconnected, Name: "sat-a2", Zone: "satellite-a", Endpoints: []string{"sat-a2"}, Since: timestamp{lastMessage}},
+		{Kind: clusterLogLag, Name: "satellite-a", Zone: "satellite-a", Endpoints: []string{"sat-a1", "sat-a2"}, LogLag: 600},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("unexpected records\n got %+v\nwant %+v", records, want)
+	}
+
+	for _, record := range buildClusterRecords(testAPIListenerStatus(), endpoints, zones, 0) {
+		if record.Kind == clusterLogLag {
+			t.Errorf("expected no replay log records when the check is disabled, got %+v", record)
+		}
+	}
+
+	if records := buildClusterRecords(nil, nil, nil, 300); records != nil {
+		t.Errorf("expected no records without ApiListener status, got %+v", records)
+	}
+}
+
+func TestRenderDashboardShowsClusterProblems(t *testing.T) {
+	withAPITestClient(t, stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{EnableNotifications: true},
+		cibStatus: &icinga2apiclient.CIBStatus{},
+		hosts:     []icinga2apiclient.Host{{Name: "web1", State: 1, StateType: 1}},
+		apiStatus: testAPIListenerStatus(),
+	})
+	oldLogLag := clusterLogLagSeconds
+	clusterLogLagSeconds = 300
+	defer func() { clusterLogLagSeconds = oldLogLag }()
+
+	rec := httptest.NewRecorder()
+	renderDashboard(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	body := rec.Body.String()
+	for _, expected := range []string{"Zone not connected (sat-b1)", "Endpoint disconnected (zone satellite-a)", "Replay log lags 10m behind"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in dashboard, got %s", expected, body)
+		}
+	}
+	if strings.Index(body, "satellite-b") > strings.Index(body, "web1") {
+		t.Errorf("expected cluster problems above host problems")
+	}
+
+	rec = httptest.NewRecorder()
+	serveAPIv2(rec, httptest.NewRequest(http.MethodGet, "/api/v2/summary", nil))
+	if !strings.Contains(rec.Body.String(), `"cluster":4`) {
+		t.Errorf("expected cluster problems in summary, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// exportRow is one problem of one host or service in a handover report.
+// Aggregated records are expanded into one row per service they represent.
+type exportRow struct {
+	Host      string
+	Service   string
+	State     string
+	StateType string
+	Since     timestamp
+}
+
+var exportHeader = []string{"host", "service", "state", "state_type", "since", "duration"}
+
+func exportRows(pageVariables PageVariables) []exportRow {
+	var rows []exportRow
+	for _, host := range pageVariables.HostRecords {
+		rows = append(rows, exportRow{
+			Host:      host.Name,
+			State:     hostStateNumToString(host.State),
+			StateType: stateTypeNumToString(host.StateType),
+			Since:     host.LastStateChange,
+		})
+		// Unreachable hosts are down as well, but their reachability is what matters
+		for _, unreachable := range host.UnreachableMembers {
+			rows = append(rows, exportRow{
+				Host:      unreachable.Name,
+				State:     "Unreachable",
+				StateType: stateTypeNumToString(unreachable.StateType),
+				Since:     unreachable.LastStateChange,
+			})
+		}
+	}
+	for _, service := range pageVariables.ServiceRecords {
+		for _, member := range service.Members {
+			rows = append(rows, exportRow{
+				Host:      member.Host,
+				Service:   member.Service,
+				State:     stateNumToString(service.State),
+				StateType: stateTypeNumToString(service.StateType),
+				Since:     member.LastStateChange,
+			})
+		}
+	}
+	return rows
+}
+
+func (row exportRow) fields(currentTime time.Time) []string {
+	since, duration := "", ""
+	if !row.Since.IsZero() {
+		since = rfc3339(row.Since)
+		duration = formatDuration(currentTime.Sub(row.Since.Time))
+	}
+	return []string{row.Host, row.Service, row.State, row.StateType, since, duration}
+}
+
+// formatDuration returns a short human readable duration like "3d 4h" or "12m".
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+func writeCSV(w io.Writer, rows []exportRow, currentTime time.Time) error {
+	writer := csv.NewWriter(w)
+	writer.Write(exportHeader)
+	for _, row := range rows {
+		writer.Write(row.fields(currentTime))
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeText(w io.Writer, rows []exportRow, currentTime time.Time) error {
+	fmt.Fprintf(w, "Problems at %s\n\n", currentTime.Format(time.RFC3339))
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.ToUpper(strings.Join(exportHeader, "\t")))
+	for _, row := range rows {
+		fields := row.fields(currentTime)
+		for i, field := range fields {
+			if field == "" {
+				fields[i] = "-"
+			}
+		}
+		fmt.Fprintln(writer, strings.Join(fields, "\t"))
+	}
+	return writer.Flush()
+}
+
+// completeRequest returns a copy of r that shows all problems, no matter
+// how many rows the screens are configured to show.
+func completeRequest(r *http.Request) *http.Request {
+	query := r.URL.Query()
+	query.Set("kiosk", kioskOff)
+	query.Set("maxRows", "0")
+
+	completeReq := r.Clone(r.Context())
+	completeReq.URL.RawQuery = query.Encode()
+	return completeReq
+}
+
+func renderCSV(w http.ResponseWriter, r *http.Request) {
+	renderExport(w, r, "csv")
+}
+
+// renderExport writes the current problems in the given format, "csv" or "text".
+func renderExport(w http.ResponseWriter, r *http.Request, format string) {
+	pageVariables := buildPageVariables(completeRequest(r), nil)
+	if pageVariables.Error != nil {
+		http.Error(w, pageVariables.Error.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rows := exportRows(pageVariables)
+	currentTime := pageVariables.Time.Time
+
+	var err error
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="problems.csv"`)
+		err = writeCSV(w, rows, currentTime)
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		err = writeText(w, rows, currentTime)
+	}
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error writing %s export: %v\n", format, err)
+	}
+}
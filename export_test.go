@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Second:              "<1m",
+		12 * time.Minute:              "12m",
+		2*time.Hour + 13*time.Minute:  "2h 13m",
+		76*time.Hour + 5*time.Minute:  "3d 4h",
+		24*time.Hour + 59*time.Second: "1d 0h",
+	}
+	for duration, want := range tests {
+		if got := formatDuration(duration); got != want {
+			t.Errorf("formatDuration(%v) = %q, want %q", duration, got, want)
+		}
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+
+	rec := httptest.NewRecorder()
+	renderCSV(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard.csv", nil))
+
+	if contentType := rec.Header().Get("Content-Type"); contentType != "text/csv; charset=utf-8" {
+		t.Errorf("expected CSV content type, got %q", contentType)
+	}
+	want := strings.Join([]string{
+		"host,service,state,state_type,since,duration",
+		"core-sw-1,,Down,Hard,2026-03-11T07:00:00Z,1h 9m",
+		"web-1,,Unreachable,Hard,2026-03-11T07:05:00Z,1h 4m",
+		"web-1,http,Critical,Hard,2026-03-11T07:00:00Z,1h 9m",
+		"web-2,http,Critical,Hard,2026-03-11T07:00:00Z,1h 9m",
+		"db-1,disk,Warning,Soft,,",
+		"",
+	}, "\n")
+	if rec.Body.String() != want {
+		t.Errorf("unexpected CSV:\n%s\nwant:\n%s", rec.Body.String(), want)
+	}
+}
+
+func TestRenderJSONAsText(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+
+	rec := httptest.NewRecorder()
+	renderJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard?format=text", nil))
+
+	if contentType := rec.Header().Get("Content-Type"); contentType != "text/plain; charset=utf-8" {
+		t.Errorf("expected plain text content type, got %q", contentType)
+	}
+	want := strings.Join([]string{
+		"Problems at 2026-03-11T08:09:10Z",
+		"",
+		"HOST       SERVICE  STATE        STATE_TYPE  SINCE                 DURATION",
+		"core-sw-1  -        Down         Hard        2026-03-11T07:00:00Z  1h 9m",
+		"web-1      -        Unreachable  Hard        2026-03-11T07:05:00Z  1h 4m",
+		"web-1      http     Critical     Hard        2026-03-11T07:00:00Z  1h 9m",
+		"web-2      http     Critical     Hard        2026-03-11T07:00:00Z  1h 9m",
+		"db-1       disk     Warning      Soft        -                     -",
+		"",
+	}, "\n")
+	if rec.Body.String() != want {
+		t.Errorf("unexpected text:\n%s\nwant:\n%s", rec.Body.String(), want)
+	}
+}
+
+func TestExportRowsGroupedByHostGroup(t *testing.T) {
+	record := buildServiceListRecord([]icinga2apiclient.Service{
+		{HostName: "db-1", ServiceName: "disk", State: 2, StateType: 1, LastStateChange: time.Unix(100, 0)},
+		{HostName: "db-2", ServiceName: "raid", State: 2, StateType: 1, LastStateChange: time.Unix(200, 0)},
+	}, "databases")
+
+	rows := exportRows(PageVariables{ServiceRecords: []PageServiceListRecord{record}})
+
+	// db-1 has no raid and db-2 no disk service
+	expected := []exportRow{
+		{Host: "db-1", Service: "disk", State: "Critical", StateType: "Hard", Since: timestamp{time.Unix(100, 0)}},
+		{Host: "db-2", Service: "raid", State: "Critical", StateType: "Hard", Since: timestamp{time.Unix(200, 0)}},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("unexpected rows: got %+v, want %+v", rows, expected)
+	}
+}
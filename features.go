@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+// Global features of Icinga2 that can be disabled at runtime, e.g. during maintenance.
+const (
+	featureNotifications = "notifications"
+	featureHostChecks    = "host_checks"
+	featureServiceChecks = "service_checks"
+	featureEventHandlers = "event_handlers"
+	featureFlapping      = "flapping"
+	featurePerfdata      = "perfdata"
+)
+
+// featureNames are shown in banners and webhook events.
+var featureNames = map[string]string{
+	featureNotifications: "Notifications",
+	featureHostChecks:    "Host checks",
+	featureServiceChecks: "Service checks",
+	featureEventHandlers: "Event handlers",
+	featureFlapping:      "Flap detection",
+	featurePerfdata:      "Performance data",
+}
+
+// disabledFeatures lists the global features disabled in app, the ones
+// hiding problems first.
+func disabledFeatures(app *icinga2apiclient.IcingaApplication) []string {
+	features := []struct {
+		name    string
+		enabled bool
+	}{
+		{featureNotifications, app.EnableNotifications},
+		{featureHostChecks, app.EnableHostChecks},
+		{featureServiceChecks, app.EnableServiceChecks},
+		{featureEventHandlers, app.EnableEventHandlers},
+		{featureFlapping, app.EnableFlapping},
+		{featurePerfdata, app.EnablePerfdata},
+	}
+
+	disabled := make([]string, 0)
+	for _, feature := range features {
+		if !feature.enabled {
+			disabled = append(disabled, feature.name)
+		}
+	}
+	return disabled
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func TestDisabledFeatures(t *testing.T) {
+	app := &icinga2apiclient.IcingaApplication{
+		EnableNotifications: true,
+		EnableHostChecks:    false,
+		EnableServiceChecks: false,
+		EnableEventHandlers: true,
+		EnableFlapping:      true,
+		EnablePerfdata:      false,
+	}
+
+	want := []string{featureHostChecks, featureServiceChecks, featurePerfdata}
+	if got := disabledFeatures(app); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	all := &icinga2apiclient.IcingaApplication{
+		EnableNotifications: true, EnableHostChecks: true, EnableServiceChecks: true,
+		EnableEventHandlers: true, EnableFlapping: true, EnablePerfdata: true,
+	}
+	if got := disabledFeatures(all); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty list, got %#v", got)
+	}
+}
+
+func TestBoardEventsForDisabledFeatures(t *testing.T) {
+	previous := boardState{DisabledFeatures: []string{featurePerfdata}}
+	current := boardState{DisabledFeatures: []string{featureServiceChecks}}
+
+	events := boardEvents(previous, current, 0)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	if events[0].Type != eventFeatureDisabled || events[0].Feature != featureServiceChecks || events[0].Title != "Service checks disabled globally" {
+		t.Errorf("unexpected event %+v", events[0])
+	}
+	if events[1].Type != eventFeatureEnabled || events[1].Feature != featurePerfdata || events[1].dedupKey != "feature_enabled|perfdata" {
+		t.Errorf("unexpected event %+v", events[1])
+	}
+}
+
+func TestBoardEventsForDisabledNotifications(t *testing.T) {
+	previous := boardState{}
+	current := boardState{NotificationsDisabled: true, DisabledFeatures: []string{featureNotifications}}
+
+	events := boardEvents(previous, current, 0)
+	if len(events) != 1 || events[0].Type != eventNotificationsDisabled {
+		t.Fatalf("expected only notifications_disabled, got %+v", events)
+	}
+	events = boardEvents(current, previous, 0)
+	if len(events) != 1 || events[0].Type != eventNotificationsEnabled {
+		t.Fatalf("expected only notifications_enabled, got %+v", events)
+	}
+}
+
+func TestRenderDashboardShowsDisabledFeatures(t *testing.T) {
+	withAPITestClient(t, stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{
+			EnableNotifications: true, EnableHostChecks: true, EnableServiceChecks: false,
+			EnableEventHandlers: true, EnableFlapping: true, EnablePerfdata: true,
+		},
+		cibStatus: &icinga2apiclient.CIBStatus{},
+	})
+
+	rec := httptest.NewRecorder()
+	renderDashboard(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "Service checks disabled globally!") {
+		t.Errorf("expected banner for disabled service checks, got %s", body)
+	}
+	if strings.Contains(body, "Notifications disabled globally!") {
+		t.Errorf("expected no banner for enabled notifications, got %s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	renderJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard", nil))
+	if !strings.Contains(rec.Body.String(), `"disabled_features":["service_checks"]`) {
+		t.Errorf("expected disabled features in JSON, got %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	serveAPIv2(rec, httptest.NewRequest(http.MethodGet, "/api/v2/summary", nil))
+	if !strings.Contains(rec.Body.String(), `"features":1`) {
+		t.Errorf("expected disabled features in summary problems, got %s", rec.Body.String())
+	}
+}
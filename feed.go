@@ -0,0 +1,206 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+)
+
+// feedEvent is a problem that appeared or recovered between two snapshots.
+type feedEvent struct {
+	ID        string
+	Recovered bool
+	Host      string
+	Service   string
+	State     string
+	Time      time.Time
+}
+
+func (e feedEvent) subject() string {
+	if e.Service != "" {
+		return e.Service + " on " + e.Host
+	}
+	return e.Host
+}
+
+func (e feedEvent) title() string {
+	if e.Recovered {
+		return "Recovered: " + e.subject()
+	}
+	return e.State + ": " + e.subject()
+}
+
+func (e feedEvent) summary() string {
+	if e.Recovered {
+		return fmt.Sprintf("%s recovered from %s at %s", e.subject(), e.State, e.Time.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s is %s since %s", e.subject(), e.State, e.Time.UTC().Format(time.RFC3339))
+}
+
+func (e feedEvent) link(baseURL string) string {
+	if e.Service != "" {
+		return baseURL + "/service?name=" + url.QueryEscape(e.Service) + "&host.name=" + url.QueryEscape(e.Host)
+	}
+	return baseURL + "/host?name=" + url.QueryEscape(e.Host)
+}
+
+// changeFeed compares successive snapshots of the problems and keeps the
+// most recent changes.
+type changeFeed struct {
+	mu          sync.Mutex
+	size        int
+	initialized bool
+	problems    map[string]exportRow
+	events      []feedEvent
+}
+
+func newChangeFeed(size int) *changeFeed {
+	return &changeFeed{size: size, problems: make(map[string]exportRow)}
+}
+
+// update records the problems of a snapshot. Problems already present in the
+// very first snapshot don't create events.
+func (f *changeFeed) update(rows []exportRow, currentTime time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	problems := make(map[string]exportRow)
+	for _, row := range rows {
+		problems[row.Host+"!"+row.Service] = row
+	}
+
+	if f.initialized {
+		for _, key := range slices.Sorted(maps.Keys(f.problems)) {
+			if _, ok := problems[key]; !ok {
+				f.add(f.problems[key], true, currentTime)
+			}
+		}
+		for _, key := range slices.Sorted(maps.Keys(problems)) {
+			if previous, ok := f.problems[key]; !ok || previous.State != problems[key].State {
+				f.add(problems[key], false, currentTime)
+			}
+		}
+	}
+	f.problems = problems
+	f.initialized = true
+}
+
+func (f *changeFeed) add(row exportRow, recovered bool, currentTime time.Time) {
+	event := feedEvent{
+		Recovered: recovered,
+		Host:      row.Host,
+		Service:   row.Service,
+		State:     row.State,
+		Time:      currentTime,
+	}
+	// The ID depends only on the event itself, so feed readers never see it twice
+	event.ID = fmt.Sprintf("urn:icinga-dashboard:event:%x", sha1.Sum([]byte(fmt.Sprintf("%s!%s!%s!%t!%d", row.Host, row.Service, row.State, recovered, currentTime.UnixNano()))))
+
+	f.events = append(f.events, event)
+	if len(f.events) > f.size {
+		f.events = f.events[len(f.events)-f.size:]
+	}
+}
+
+// recent returns the recorded events, newest first.
+func (f *changeFeed) recent() []feedEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	events := make([]feedEvent, 0, len(f.events))
+	for i := len(f.events) - 1; i >= 0; i-- {
+		events = append(events, f.events[i])
+	}
+	return events
+}
+
+// watchSnapshots takes a snapshot with the default settings every interval
+// and hands it to all observers. Failed snapshots are skipped, so an
+// unavailable Icinga2 API doesn't look like every problem recovered.
+func watchSnapshots(interval time.Duration, observers ...func(PageVariables)) {
+	for {
+		takeSnapshot(observers...)
+		time.Sleep(interval)
+	}
+}
+
+// takeSnapshot hands one complete snapshot with the default settings to all
+// observers, unless it failed.
+func takeSnapshot(observers ...func(PageVariables)) {
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	pageVariables := buildPageVariables(completeRequest(req), nil)
+	if pageVariables.Error != nil {
+		return
+	}
+	for _, observe := range observers {
+		observe(pageVariables)
+	}
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary"`
+}
+
+func buildAtomFeed(events []feedEvent, baseURL string, currentTime time.Time) atomFeed {
+	atom := atomFeed{
+		Title:   "Icinga2 Dashboard problems",
+		ID:      "urn:icinga-dashboard:feed",
+		Updated: currentTime.UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: "Icinga2 Dashboard"},
+		Link:    atomLink{Href: baseURL},
+	}
+	if len(events) > 0 {
+		atom.Updated = events[0].Time.UTC().Format(time.RFC3339)
+	}
+
+	for _, event := range events {
+		atom.Entries = append(atom.Entries, atomEntry{
+			Title:   event.title(),
+			ID:      event.ID,
+			Updated: event.Time.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: event.link(baseURL)},
+			Summary: event.summary(),
+		})
+	}
+	return atom
+}
+
+func renderFeed(w http.ResponseWriter, r *http.Request) {
+	atom := buildAtomFeed(problemFeed.recent(), baseURL, now())
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(atom); err != nil {
+		fmt.Fprintf(logOutput, "Error writing feed: %v\n", err)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChangeFeedUpdate(t *testing.T) {
+	feed := newChangeFeed(10)
+	start := time.Date(2026, time.March, 11, 8, 0, 0, 0, time.UTC)
+
+	// Problems of the first snapshot are known already
+	feed.update([]exportRow{{Host: "web-1", Service: "http", State: "Warning"}}, start)
+	if events := feed.recent(); len(events) != 0 {
+		t.Fatalf("expected no events for the first snapshot, got %+v", events)
+	}
+
+	feed.update([]exportRow{
+		{Host: "web-1", Service: "http", State: "Critical"},
+		{Host: "core-sw-1", State: "Down"},
+	}, start.Add(time.Minute))
+	feed.update([]exportRow{{Host: "core-sw-1", State: "Down"}}, start.Add(2*time.Minute))
+
+	events := feed.recent()
+	titles := make([]string, len(events))
+	for i, event := range events {
+		titles[i] = event.title()
+	}
+	want := []string{"Recovered: http on web-1", "Critical: http on web-1", "Down: core-sw-1"}
+	if strings.Join(titles, "|") != strings.Join(want, "|") {
+		t.Errorf("expected events %v, got %v", want, titles)
+	}
+	if events[0].ID == events[1].ID {
+		t.Error("expected distinct event IDs")
+	}
+	if events[1].ID != feed.recent()[1].ID {
+		t.Error("expected stable event IDs")
+	}
+}
+
+func TestChangeFeedKeepsMostRecentEvents(t *testing.T) {
+	feed := newChangeFeed(2)
+	start := time.Date(2026, time.March, 11, 8, 0, 0, 0, time.UTC)
+
+	feed.update(nil, start)
+	for i, host := range []string{"a", "b", "c"} {
+		feed.update([]exportRow{{Host: host, State: "Down"}}, start.Add(time.Duration(i+1)*time.Minute))
+	}
+
+	events := feed.recent()
+	if len(events) != 2 || events[0].Host != "c" || events[0].Recovered || !events[1].Recovered || events[1].Host != "b" {
+		t.Errorf("expected the 2 most recent events, got %+v", events)
+	}
+}
+
+func TestRenderFeed(t *testing.T) {
+	originalFeed := problemFeed
+	originalBaseURL := baseURL
+	defer func() {
+		problemFeed = originalFeed
+		baseURL = originalBaseURL
+	}()
+
+	baseURL = "https://icinga.example.test/icingaweb2/icingadb"
+	problemFeed = newChangeFeed(10)
+	start := time.Date(2026, time.March, 11, 8, 0, 0, 0, time.UTC)
+	problemFeed.update(nil, start)
+	problemFeed.update([]exportRow{{Host: "web 1", Service: "http", State: "Critical"}}, start.Add(time.Minute))
+
+	rec := httptest.NewRecorder()
+	renderFeed(rec, httptest.NewRequest(http.MethodGet, "/feed.atom", nil))
+
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/atom+xml; charset=utf-8" {
+		t.Errorf("expected Atom content type, got %q", contentType)
+	}
+
+	var feed atomFeed
+	if err := xml.Unmarshal(rec.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("expected valid Atom XML, got %v", err)
+	}
+	if feed.Updated != "2026-03-11T08:01:00Z" || len(feed.Entries) != 1 {
+		t.Fatalf("unexpected feed %+v", feed)
+	}
+	entry := feed.Entries[0]
+	if entry.Title != "Critical: http on web 1" {
+		t.Errorf("unexpected title %q", entry.Title)
+	}
+	if entry.Link.Href != "https://icinga.example.test/icingaweb2/icingadb/service?name=http&host.name=web+1" {
+		t.Errorf("unexpected link %q", entry.Link.Href)
+	}
+	if !strings.HasPrefix(entry.ID, "urn:icinga-dashboard:event:") {
+		t.Errorf("unexpected ID %q", entry.ID)
+	}
+}
+
+func TestTakeSnapshotSkipsFailedServiceFetch(t *testing.T) {
+	stub := apiTestClient()
+	withAPITestClient(t, stub)
+	feed := newChangeFeed(10)
+	observe := func(pageVariables PageVariables) {
+		feed.update(exportRows(pageVariables), pageVariables.Time.Time)
+	}
+
+	takeSnapshot(observe)
+	stub.servicesErr = errors.New("connection reset")
+	client = stub
+	takeSnapshot(observe)
+	stub.servicesErr = nil
+	client = stub
+	takeSnapshot(observe)
+
+	if events := feed.recent(); len(events) != 0 {
+		t.Errorf("expected a failed snapshot not to recover and reopen problems, got %+v", events)
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+const (
+	expandNone  = "none"
+	expandAll   = "all"
+	expandCycle = "cycle"
+)
+
+const (
+	groupByService   = "service"
+	groupByHost      = "host"
+	groupByHostGroup = "hostgroup"
+	groupByNone      = "none"
+	groupByVarPrefix = "vars."
+)
+
+// serviceGrouping decides which services end up in the same dashboard row.
+type serviceGrouping struct {
+	// One of "service", "host", "hostgroup", "none" or "vars.<name>"
+	By string
+	// Minimum number of services a group needs before it is collapsed into one row
+	Threshold int
+}
+
+func isValidGroupBy(groupBy string) bool {
+	switch groupBy {
+	case groupByService, groupByHost, groupByHostGroup, groupByNone:
+		return true
+	}
+	return strings.HasPrefix(groupBy, groupByVarPrefix) && len(groupBy) > len(groupByVarPrefix)
+}
+
+// groupName returns the name of the group a service belongs to.
+// The second return value is false if the service can't be grouped.
+// Every service is shown once, so a service whose host is in several host
+// groups belongs to the alphabetically first of them.
+func (g serviceGrouping) groupName(service icinga2apiclient.Service) (string, bool) {
+	switch {
+	case g.By == groupByService:
+		return service.ServiceName, true
+	case g.By == groupByHost:
+		return service.HostName, true
+	case g.By == groupByHostGroup:
+		if len(service.HostGroups) == 0 {
+			return "", false
+		}
+		groups := append([]string(nil), service.HostGroups...)
+		sort.Strings(groups)
+		return groups[0], true
+	case strings.HasPrefix(g.By, groupByVarPrefix):
+		value, ok := lookupVar(service.Vars, strings.TrimPrefix(g.By, groupByVarPrefix))
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprint(value), true
+	}
+
+	return "", false
+}
+
+// lookupVar resolves a dotted path like "team" or "owner.name" within custom vars.
+func lookupVar(vars map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = vars
+	for _, key := range strings.Split(path, ".") {
+		asMap, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = asMap[key]
+		if !ok || current == nil {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// lookupPriority returns the value of the priority custom var, if set.
+func lookupPriority(vars map[string]interface{}) string {
+	value, ok := lookupVar(vars, priorityVar)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func buildServiceListRecords(services []icinga2apiclient.Service, grouping serviceGrouping) []PageServiceListRecord {
+	groupedServices := make(map[string][]icinga2apiclient.Service)
+	groupNames := make(map[string]string)
+	var groupKeys []string
+	var ungrouped [][]icinga2apiclient.Service
+
+	for _, service := range services {
+		name, ok := grouping.groupName(service)
+		if !ok {
+			ungrouped = append(ungrouped, []icinga2apiclient.Service{service})
+			continue
+		}
+
+		// Keep state and state type in the key so every row has a single color
+		groupKey := fmt.Sprintf("%s-%d-%d", name, service.State, service.StateType)
+		if _, exists := groupedServices[groupKey]; !exists {
+			groupKeys = append(groupKeys, groupKey)
+		}
+		groupedServices[groupKey] = append(groupedServices[groupKey], service)
+		groupNames[groupKey] = name
+	}
+
+	threshold := grouping.Threshold
+	if threshold < 2 {
+		threshold = 2
+	}
+
+	var resultSet []PageServiceListRecord
+
+	for _, groupKey := range groupKeys {
+		group := groupedServices[groupKey]
+		if len(group) < threshold {
+			for _, service := range group {
+				resultSet = append(resultSet, buildServiceListRecord([]icinga2apiclient.Service{service}, groupNames[groupKey]))
+			}
+			continue
+		}
+		resultSet = append(resultSet, buildServiceListRecord(group, groupNames[groupKey]))
+	}
+
+	for _, group := range ungrouped {
+		resultSet = append(resultSet, buildServiceListRecord(group, ""))
+	}
+
+	return resultSet
+}
+
+func buildServiceListRecord(group []icinga2apiclient.Service, groupName string) PageServiceListRecord {
+	hosts := uniqueStrings(group, func(service icinga2apiclient.Service) string { return service.HostName })
+	serviceNames := uniqueStrings(group, func(service icinga2apiclient.Service) string { return service.ServiceName })
+
+	hostField := hosts[0]
+	if len(hosts) > 1 {
+		hostField = fmt.Sprintf("%d Hosts", len(hosts))
+	}
+	name := serviceNames[0]
+	if len(serviceNames) > 1 {
+		name = fmt.Sprintf("%d Services", len(serviceNames))
+	}
+
+	since := group[0].LastStateChange
+	lastStateChange := group[0].LastStateChange
+	var priority string
+	weight := 0
+	var members []PageServiceMember
+	for _, service := range group {
+		members = append(members, PageServiceMember{
+			Host:            service.HostName,
+			Service:         service.ServiceName,
+			LastStateChange: timestamp{service.LastStateChange},
+		})
+		if serviceWeight := weighting.weigh(service); serviceWeight > weight {
+			weight = serviceWeight
+		}
+		if service.LastStateChange.Before(since) {
+			since = service.LastStateChange
+		}
+		if service.LastStateChange.After(lastStateChange) {
+			lastStateChange = service.LastStateChange
+		}
+		// The most important service decides about the priority of the whole record
+		if servicePriority := lookupPriority(service.Vars); servicePriority != "" && (priority == "" || lessPriority(servicePriority, priority)) {
+			priority = servicePriority
+		}
+	}
+
+	return PageServiceListRecord{
+		HostField:               hostField,
+		Name:                    name,
+		GroupName:               groupName,
+		State:                   group[0].State,
+		StateType:               group[0].StateType,
+		IsAggregated:            len(group) > 1,
+		AggregatedHosts:         hosts,
+		AggregatedHostsCount:    len(hosts),
+		AggregatedServices:      serviceNames,
+		AggregatedServicesCount: len(serviceNames),
+		Members:                 members,
+		Since:                   timestamp{since},
+		LastStateChange:         timestamp{lastStateChange},
+		Priority:                priority,
+		Weight:                  weight,
+		WeightLevel:             weighting.level(weight),
+	}
+}
+
+// uniqueStrings returns the distinct values of field in the order they first appear.
+func uniqueStrings(group []icinga2apiclient.Service, field func(icinga2apiclient.Service) string) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, service := range group {
+		value := field(service)
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		values = append(values, value)
+	}
+	return values
+}
+
+func isValidExpandMode(mode string) bool {
+	return mode == expandNone || mode == expandAll || mode == expandCycle
+}
+
+// applyExpandMode marks aggregated records as expanded. In cycle mode only one
+// aggregated record is expanded at a time, moving on with every page refresh.
+func applyExpandMode(records []PageServiceListRecord, mode string, currentTime time.Time) {
+	var aggregated []int
+	for i := range records {
+		if records[i].IsAggregated {
+			aggregated = append(aggregated, i)
+		}
+	}
+
+	switch mode {
+	case expandAll:
+		for _, i := range aggregated {
+			records[i].Expanded = true
+		}
+	case expandCycle:
+		if len(aggregated) == 0 {
+			return
+		}
+		step := currentTime.Unix() / refreshIntervalSeconds
+		records[aggregated[step%int64(len(aggregated))]].Expanded = true
+	}
+}
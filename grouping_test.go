@@ -0,0 +1,247 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func TestIsValidGroupBy(t *testing.T) {
+	for _, value := range []string{"service", "host", "hostgroup", "none", "vars.team"} {
+		if !isValidGroupBy(value) {
+			t.Errorf("expected %q to be valid", value)
+		}
+	}
+	for _, value := range []string{"", "vars.", "team", "Service"} {
+		if isValidGroupBy(value) {
+			t.Errorf("expected %q to be invalid", value)
+		}
+	}
+}
+
+func TestBuildServiceListRecords_GroupByHost(t *testing.T) {
+	services := []icinga2apiclient.Service{
+		{HostName: "host-a", ServiceName: "disk", State: 2, StateType: 1},
+		{HostName: "host-a", ServiceName: "load", State: 2, StateType: 1},
+		{HostName: "host-b", ServiceName: "disk", State: 2, StateType: 1},
+	}
+
+	records := buildServiceListRecords(services, serviceGrouping{By: groupByHost, Threshold: 2})
+	sort.Sort(ByState(records))
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].HostField != "host-a" || records[0].Name != "2 Services" || !records[0].IsAggregated {
+		t.Errorf("unexpected aggregated record: %+v", records[0])
+	}
+	if records[0].AggregatedServicesCount != 2 || records[0].AggregatedHostsCount != 1 {
+		t.Errorf("unexpected aggregation counts: %+v", records[0])
+	}
+	if records[1].HostField != "host-b" || records[1].Name != "disk" || records[1].IsAggregated {
+		t.Errorf("unexpected single record: %+v", records[1])
+	}
+}
+
+func TestBuildServiceListRecords_GroupByHostGroup(t *testing.T) {
+	services := []icinga2apiclient.Service{
+		{HostName: "db-1", ServiceName: "mysql", State: 2, StateType: 1, HostGroups: []string{"linux", "databases"}},
+		{HostName: "db-2", ServiceName: "disk", State: 2, StateType: 1, HostGroups: []string{"databases"}},
+		{HostName: "web-1", ServiceName: "http", State: 2, StateType: 1},
+	}
+
+	records := buildServiceListRecords(services, serviceGrouping{By: groupByHostGroup, Threshold: 2})
+	sort.Sort(ByState(records))
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	// db-1 is in linux as well, but shows only in its alphabetically first group
+	if records[0].GroupName != "databases" || records[0].HostField != "2 Hosts" || records[0].Name != "2 Services" {
+		t.Errorf("unexpected host group record: %+v", records[0])
+	}
+	if records[1].HostField != "web-1" || records[1].IsAggregated {
+		t.Errorf("expected host without group to stay on its own, got %+v", records[1])
+	}
+}
+
+func TestBuildServiceListRecords_GroupByVar(t *testing.T) {
+	services := []icinga2apiclient.Service{
+		{HostName: "host-a", ServiceName: "disk", State: 1, StateType: 1, Vars: map[string]interface{}{"team": "storage"}},
+		{HostName: "host-b", ServiceName: "raid", State: 1, StateType: 1, Vars: map[string]interface{}{"team": "storage"}},
+		{HostName: "host-c", ServiceName: "raid", State: 2, StateType: 1, Vars: map[string]interface{}{"team": "storage"}},
+		{HostName: "host-d", ServiceName: "http", State: 1, StateType: 1, Vars: map[string]interface{}{"team": "web"}},
+	}
+
+	records := buildServiceListRecords(services, serviceGrouping{By: "vars.team", Threshold: 2})
+
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d: %+v", len(records), records)
+	}
+	var storageWarnings *PageServiceListRecord
+	for i := range records {
+		if records[i].GroupName == "storage" && records[i].State == 1 {
+			storageWarnings = &records[i]
+		}
+	}
+	if storageWarnings == nil || storageWarnings.AggregatedHostsCount != 2 || !storageWarnings.IsAggregated {
+		t.Errorf("expected storage warnings to be aggregated, got %+v", records)
+	}
+}
+
+func TestBuildServiceListRecords_Threshold(t *testing.T) {
+	services := []icinga2apiclient.Service{
+		{HostName: "host-a", ServiceName: "disk", State: 2, StateType: 1},
+		{HostName: "host-b", ServiceName: "disk", State: 2, StateType: 1},
+		{HostName: "host-c", ServiceName: "ping", State: 2, StateType: 1},
+		{HostName: "host-d", ServiceName: "ping", State: 2, StateType: 1},
+		{HostName: "host-e", ServiceName: "ping", State: 2, StateType: 1},
+	}
+
+	records := buildServiceListRecords(services, serviceGrouping{By: groupByService, Threshold: 3})
+	sort.Sort(ByState(records))
+
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if records[0].Name != "disk" || records[0].IsAggregated || records[1].Name != "disk" || records[1].IsAggregated {
+		t.Errorf("expected disk to stay below the threshold, got %+v", records[:2])
+	}
+	if records[2].Name != "ping" || records[2].HostField != "3 Hosts" {
+		t.Errorf("expected ping to be aggregated, got %+v", records[2])
+	}
+}
+
+func TestBuildServiceListRecords_NoGrouping(t *testing.T) {
+	services := []icinga2apiclient.Service{
+		{HostName: "host-a", ServiceName: "disk", State: 2, StateType: 1},
+		{HostName: "host-b", ServiceName: "disk", State: 2, StateType: 1},
+	}
+
+	records := buildServiceListRecords(services, serviceGrouping{By: groupByNone})
+
+	if len(records) != 2 || records[0].IsAggregated || records[1].IsAggregated {
+		t.Errorf("expected no aggregation, got %+v", records)
+	}
+}
+
+func TestLookupVar(t *testing.T) {
+	vars := map[string]interface{}{
+		"team":  "ops",
+		"owner": map[string]interface{}{"name": "alice"},
+	}
+
+	if value, ok := lookupVar(vars, "team"); !ok || value != "ops" {
+		t.Errorf("expected ops, got %v", value)
+	}
+	if value, ok := lookupVar(vars, "owner.name"); !ok || value != "alice" {
+		t.Errorf("expected alice, got %v", value)
+	}
+	if _, ok := lookupVar(vars, "team.name"); ok {
+		t.Errorf("expected lookup into a string to fail")
+	}
+	if _, ok := lookupVar(nil, "team"); ok {
+		t.Errorf("expected lookup in nil vars to fail")
+	}
+}
+
+func TestApplyExpandMode(t *testing.T) {
+	newRecords := func() []PageServiceListRecord {
+		return []PageServiceListRecord{
+			{Name: "disk", IsAggregated: true},
+			{Name: "ping"},
+			{Name: "load", IsAggregated: true},
+		}
+	}
+
+	records := newRecords()
+	applyExpandMode(records, expandNone, time.Unix(0, 0))
+	for _, record := range records {
+		if record.Expanded {
+			t.Errorf("expected %q not to be expanded", record.Name)
+		}
+	}
+
+	records = newRecords()
+	applyExpandMode(records, expandAll, time.Unix(0, 0))
+	if !records[0].Expanded || records[1].Expanded || !records[2].Expanded {
+		t.Errorf("expected all aggregated records to be expanded, got %+v", records)
+	}
+
+	records = newRecords()
+	applyExpandMode(records, expandCycle, time.Unix(0, 0))
+	if !records[0].Expanded || records[2].Expanded {
+		t.Errorf("expected first aggregated record to be expanded, got %+v", records)
+	}
+
+	records = newRecords()
+	applyExpandMode(records, expandCycle, time.Unix(refreshIntervalSeconds, 0))
+	if records[0].Expanded || !records[2].Expanded {
+		t.Errorf("expected second aggregated record to be expanded after one refresh, got %+v", records)
+	}
+}
+
+func TestBuildServiceListRecords_StateChangesAndPriority(t *testing.T) {
+	originalPriorityVar := priorityVar
+	originalWeighting := weighting
+	defer func() {
+		priorityVar = originalPriorityVar
+		weighting = originalWeighting
+	}()
+	priorityVar = "priority"
+	weighting = severityWeighting{
+		Rules: []severityRule{
+			{Kind: "state", Value: "2", Weight: 30},
+			{Kind: "vars", Path: "priority", Value: "P1", Weight: 40},
+		},
+		Thresholds: []int{50},
+	}
+
+	services := []icinga2apiclient.Service{
+		{HostName: "host-a", ServiceName: "disk", State: 2, StateType: 1, LastStateChange: time.Unix(200, 0), Vars: map[string]interface{}{"priority": "P3"}},
+		{HostName: "host-b", ServiceName: "disk", State: 2, StateType: 1, LastStateChange: time.Unix(100, 0), Vars: map[string]interface{}{"priority": "P1"}},
+		{HostName: "host-c", ServiceName: "disk", State: 2, StateType: 1, LastStateChange: time.Unix(300, 0)},
+	}
+
+	records := buildServiceListRecords(services, serviceGrouping{By: groupByService, Threshold: 2})
+
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	if records[0].Since.Unix() != 100 || records[0].LastStateChange.Unix() != 300 {
+		t.Errorf("unexpected state changes: since=%d last=%d", records[0].Since.Unix(), records[0].LastStateChange.Unix())
+	}
+	if records[0].Priority != "P1" {
+		t.Errorf("expected the highest priority P1, got %q", records[0].Priority)
+	}
+	if records[0].Weight != 70 || records[0].WeightLevel != 1 {
+		t.Errorf("expected the highest weight 70 at level 1, got %d at level %d", records[0].Weight, records[0].WeightLevel)
+	}
+}
+
+func TestBuildServiceListRecords_Members(t *testing.T) {
+	services := []icinga2apiclient.Service{
+		{HostName: "host-a", ServiceName: "disk", State: 1, StateType: 1, LastStateChange: time.Unix(100, 0), Vars: map[string]interface{}{"team": "storage"}},
+		{HostName: "host-b", ServiceName: "raid", State: 1, StateType: 1, LastStateChange: time.Unix(200, 0), Vars: map[string]interface{}{"team": "storage"}},
+	}
+
+	records := buildServiceListRecords(services, serviceGrouping{By: "vars.team", Threshold: 2})
+
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	// Only the services that exist, not every host with every service
+	expected := []PageServiceMember{
+		{Host: "host-a", Service: "disk", LastStateChange: timestamp{time.Unix(100, 0)}},
+		{Host: "host-b", Service: "raid", LastStateChange: timestamp{time.Unix(200, 0)}},
+	}
+	if !reflect.DeepEqual(records[0].Members, expected) {
+		t.Errorf("unexpected members: got %+v, want %+v", records[0].Members, expected)
+	}
+	if members := records[0].HostMembers("host-b"); len(members) != 1 || members[0].Service != "raid" {
+		t.Errorf("unexpected members of host-b: %+v", members)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultLanguageName = "en"
+
+// catalog maps message IDs to translated texts. Texts may contain fmt verbs.
+type catalog map[string]string
+
+var (
+	catalogs        = mustLoadCatalogs(embeddedFiles)
+	defaultLanguage = defaultLanguageName
+)
+
+// loadCatalogs reads all locales/<language>.json files. Language tags are
+// case-insensitive, so the catalogs are keyed by the lowercased file name.
+func loadCatalogs(fsys fs.FS) (map[string]catalog, error) {
+	loaded := make(map[string]catalog)
+
+	names, err := fs.Glob(fsys, "locales/*.json")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var messages catalog
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %w", name, err)
+		}
+		loaded[strings.ToLower(strings.TrimSuffix(path.Base(name), ".json"))] = messages
+	}
+
+	return loaded, nil
+}
+
+func mustLoadCatalogs(fsys fs.FS) map[string]catalog {
+	loaded, err := loadCatalogs(fsys)
+	if err != nil {
+		panic(err)
+	}
+	return loaded
+}
+
+// localizer translates messages into one language, falling back to English
+// for messages missing in the catalog. The zero value translates to English.
+type localizer struct {
+	Language string
+	messages catalog
+}
+
+func newLocalizer(language string) localizer {
+	return localizer{
+		Language: language,
+		messages: catalogs[language],
+	}
+}
+
+// T returns the translation of the message with the given ID, formatted with
+// args. Unknown IDs are returned as they are.
+func (l localizer) T(id string, args ...interface{}) string {
+	message, ok := l.messages[id]
+	if !ok {
+		message, ok = catalogs[defaultLanguageName][id]
+	}
+	if !ok {
+		message = id
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// negotiateLanguage picks the language for a request. The query parameter
+// "lang" wins over the Accept-Language header, which wins over the default.
+func negotiateLanguage(r *http.Request, query map[string][]string) string {
+	if values := query["lang"]; len(values) > 0 {
+		if language, ok := matchLanguage(values[0]); ok {
+			return language
+		}
+	}
+
+	for _, accepted := range parseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if language, ok := matchLanguage(accepted); ok {
+			return language
+		}
+	}
+
+	return defaultLanguage
+}
+
+// matchLanguage finds a catalog for a language tag like "de-AT", trying the
+// full tag first and the base language second.
+func matchLanguage(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if _, ok := catalogs[tag]; ok {
+		return tag, true
+	}
+	base, _, _ := strings.Cut(tag, "-")
+	if _, ok := catalogs[base]; ok {
+		return base, true
+	}
+	return "", false
+}
+
+// parseAcceptLanguage returns the languages of an Accept-Language header,
+// most preferred first.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var languages []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			languages = append(languages, weighted{tag, quality})
+		}
+	}
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].quality > languages[j].quality })
+
+	var tags []string
+	for _, language := range languages {
+		tags = append(tags, language.tag)
+	}
+	return tags
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func TestEmbeddedCatalogsAreComplete(t *testing.T) {
+	loaded, err := loadCatalogs(embeddedFiles)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, language := range []string{"de", "es"} {
+		messages, ok := loaded[language]
+		if !ok {
+			t.Fatalf("expected catalog for %s", language)
+		}
+		for id := range loaded[defaultLanguageName] {
+			if _, ok := messages[id]; !ok && !strings.HasSuffix(id, ".---") {
+				t.Errorf("catalog %s misses message %s", language, id)
+			}
+		}
+	}
+}
+
+func TestLocalizerT(t *testing.T) {
+	german := newLocalizer("de")
+	if got := german.T("hosts_unreachable", german.T("state.Down"), 3); got != "Down (3 Hosts nicht erreichbar)" {
+		t.Errorf("unexpected translation %q", got)
+	}
+	spanish := newLocalizer("es")
+	if got := spanish.T("host_unreachable", spanish.T("state.Down"), 1); got != "Caído (1 host inalcanzable)" {
+		t.Errorf("unexpected translation %q", got)
+	}
+	// Missing translations fall back to English
+	if got := german.T("state.---"); got != "---" {
+		t.Errorf("unexpected fallback %q", got)
+	}
+	if got := german.T("unknown_message"); got != "unknown_message" {
+		t.Errorf("expected unknown message IDs to be returned as they are, got %q", got)
+	}
+	if got := (localizer{}).T("n_hosts", 2); got != "2 Hosts" {
+		t.Errorf("expected zero localizer to translate to English, got %q", got)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := parseAcceptLanguage("fr;q=0.5, de-AT, es;q=0.8, *;q=0.1, it;q=0")
+	want := []string{"de-AT", "es", "fr"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	tests := []struct {
+		url            string
+		acceptLanguage string
+		want           string
+	}{
+		{"/", "", "en"},
+		{"/", "de-DE,de;q=0.9,en;q=0.8", "de"},
+		{"/", "fr-FR, es;q=0.5", "es"},
+		{"/", "fr-FR", "en"},
+		{"/?lang=es", "de-DE", "es"},
+		{"/?lang=fr", "de-DE", "de"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.url, nil)
+		req.Header.Set("Accept-Language", test.acceptLanguage)
+		if got := negotiateLanguage(req, req.URL.Query()); got != test.want {
+			t.Errorf("%s with %q: expected %s, got %s", test.url, test.acceptLanguage, test.want, got)
+		}
+	}
+}
+
+func TestNegotiateRegionLanguage(t *testing.T) {
+	originalCatalogs := catalogs
+	defer func() { catalogs = originalCatalogs }()
+	var err error
+	catalogs, err = loadCatalogs(fstest.MapFS{
+		"locales/en.json":    {Data: []byte(`{"new": "NEW"}`)},
+		"locales/pt.json":    {Data: []byte(`{"new": "NOVO"}`)},
+		"locales/pt-BR.json": {Data: []byte(`{"new": "NOVO!"}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		url            string
+		acceptLanguage string
+		want           string
+	}{
+		{"/?lang=pt-BR", "", "pt-br"},
+		{"/", "pt-BR,pt;q=0.9", "pt-br"},
+		{"/", "PT-br", "pt-br"},
+		{"/", "pt-PT", "pt"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, test.url, nil)
+		req.Header.Set("Accept-Language", test.acceptLanguage)
+		if got := negotiateLanguage(req, req.URL.Query()); got != test.want {
+			t.Errorf("%s with %q: expected %s, got %s", test.url, test.acceptLanguage, test.want, got)
+		}
+	}
+	if got := newLocalizer("pt-br").T("new"); got != "NOVO!" {
+		t.Errorf("expected the regional catalog, got %q", got)
+	}
+}
+
+func TestRenderDashboardInGerman(t *testing.T) {
+	originalClient := client
+	originalNow := now
+	defer func() {
+		client = originalClient
+		now = originalNow
+	}()
+
+	client = stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{EnableNotifications: false},
+		cibStatus: &icinga2apiclient.CIBStatus{},
+		hosts: []icinga2apiclient.Host{
+			{Name: "core-sw-1", State: 1, StateType: 1},
+		},
+	}
+	now = func() time.Time {
+		return time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?minState=2", nil)
+	req.Header.Set("Accept-Language", "de-DE,de;q=0.9")
+	rec := httptest.NewRecorder()
+
+	renderDashboard(rec, req)
+
+	body := rec.Body.String()
+	for _, expected := range []string{
+		`<html lang="de">`,
+		"Minimaler Status: Kritisch",
+		"Benachrichtigungen global deaktiviert!",
+		"08:09:10",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in rendered dashboard, got %s", expected, body)
+		}
+	}
+}
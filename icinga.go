@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+// icingaInfo describes the Icinga2 instance the dashboard talks to.
+type icingaInfo struct {
+	Version      string    `json:"version"`
+	NodeName     string    `json:"node_name"`
+	Environment  string    `json:"environment"`
+	PID          int       `json:"pid"`
+	ProgramStart timestamp `json:"program_start"`
+	// Seconds since ProgramStart
+	Uptime int `json:"uptime"`
+	// Icinga2 started less than RESTART_BANNER_SECONDS ago
+	RecentlyRestarted  bool   `json:"recently_restarted"`
+	ProgramStartString string `json:"-"`
+}
+
+// MarshalJSON adds an RFC3339 variant of the unix timestamp.
+func (i icingaInfo) MarshalJSON() ([]byte, error) {
+	type info icingaInfo
+	return json.Marshal(struct {
+		info
+		ProgramStartRFC3339 string `json:"program_start_rfc3339"`
+	}{info(i), rfc3339(i.ProgramStart)})
+}
+
+// UptimeString returns the uptime like "3d 4h".
+func (i icingaInfo) UptimeString() string {
+	return formatDuration(time.Duration(i.Uptime) * time.Second)
+}
+
+// newIcingaInfo shows the program start in location, formatted with layout.
+// A restart within restartWindow is reported as RecentlyRestarted.
+func newIcingaInfo(app *icinga2apiclient.IcingaApplication, currentTime time.Time, location *time.Location, layout string, restartWindow time.Duration) *icingaInfo {
+	info := &icingaInfo{
+		Version:     app.Version,
+		NodeName:    app.NodeName,
+		Environment: app.Environment,
+		PID:         app.PID,
+	}
+	if app.ProgramStart > 0 {
+		programStart := time.Unix(int64(app.ProgramStart), 0)
+		info.ProgramStart = timestamp{programStart}
+		info.ProgramStartString = programStart.In(location).Format(layout)
+		info.Uptime = max(int(currentTime.Sub(programStart).Seconds()), 0)
+		info.RecentlyRestarted = restartWindow > 0 && currentTime.Sub(programStart) < restartWindow
+	}
+	return info
+}
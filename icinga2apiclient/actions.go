@@ -0,0 +1,69 @@
+package icinga2apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// AcknowledgeProblem acknowledges the problems of the target until they recover.
+func (client *Client) AcknowledgeProblem(target ActionTarget, author string, comment string) error {
+	payload := target.payload(author, comment)
+	return client.postAction("acknowledge-problem", payload)
+}
+
+// ScheduleDowntime schedules a fixed downtime from start to end for the target.
+func (client *Client) ScheduleDowntime(target ActionTarget, author string, comment string, start time.Time, end time.Time) error {
+	payload := target.payload(author, comment)
+	payload.StartTime = start.Unix()
+	payload.EndTime = end.Unix()
+	payload.Fixed = true
+	return client.postAction("schedule-downtime", payload)
+}
+
+// postAction calls an action of /v1/actions, which are real POST requests
+// unlike the object queries.
+func (client *Client) postAction(action string, payload actionPayload) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error marshaling JSON payload: %v\n", err)
+		return err
+	}
+
+	req, err := client.newRequest(http.MethodPost, "/v1/actions/"+action, jsonPayload)
+	if err != nil {
+		return err
+	}
+	_, err = client.doRequest(req)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error calling action %s: %v\n", action, err)
+	}
+	return err
+}
+
+// payload selects the target objects through filter variables, so names
+// don't need to be quoted for the Icinga2 DSL.
+func (target ActionTarget) payload(author string, comment string) actionPayload {
+	if len(target.Services) == 0 {
+		return actionPayload{
+			Type:       "Host",
+			Filter:     "host.name in hosts",
+			FilterVars: map[string]interface{}{"hosts": target.Hosts},
+			Author:     author,
+			Comment:    comment,
+		}
+	}
+
+	var names []string
+	for _, service := range target.Services {
+		names = append(names, service.FullName())
+	}
+	return actionPayload{
+		Type:       "Service",
+		Filter:     "service.__name in services",
+		FilterVars: map[string]interface{}{"services": names},
+		Author:     author,
+		Comment:    comment,
+	}
+}
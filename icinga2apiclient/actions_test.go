@@ -0,0 +1,88 @@
+package icinga2apiclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_AcknowledgeProblem_Integration(t *testing.T) {
+	ts := NewTestIntegrationServer()
+	defer ts.Server.Close()
+
+	client := &Client{
+		httpClient: http.DefaultClient,
+		Hostname:   ts.Server.URL,
+	}
+	// host1 has no service2 and host2 no service1
+	target := ActionTarget{Services: []ServiceName{{Host: "host1", Service: "service1"}, {Host: "host2", Service: "service2"}}}
+	if err := client.AcknowledgeProblem(target, "jdoe", "looking into it"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if ts.LastRequestPath != "/v1/actions/acknowledge-problem" {
+		t.Errorf("unexpected path %s", ts.LastRequestPath)
+	}
+	if ts.LastMethodOverride != "" {
+		t.Errorf("expected a real POST, got X-HTTP-Method-Override %s", ts.LastMethodOverride)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(ts.LastRequestBody), &payload); err != nil {
+		t.Fatalf("unexpected payload %s", ts.LastRequestBody)
+	}
+	expected := map[string]interface{}{
+		"type":        "Service",
+		"filter":      "service.__name in services",
+		"filter_vars": map[string]interface{}{"services": []interface{}{"host1!service1", "host2!service2"}},
+		"author":      "jdoe",
+		"comment":     "looking into it",
+	}
+	if !reflect.DeepEqual(payload, expected) {
+		t.Errorf("unexpected payload: got %v, want %v", payload, expected)
+	}
+}
+
+func TestClient_ScheduleDowntime_Integration(t *testing.T) {
+	ts := NewTestIntegrationServer()
+	defer ts.Server.Close()
+
+	client := &Client{
+		httpClient: http.DefaultClient,
+		Hostname:   ts.Server.URL,
+	}
+	start := time.Unix(1700000000, 0)
+	if err := client.ScheduleDowntime(ActionTarget{Hosts: []string{"host1"}}, "jdoe", "maintenance", start, start.Add(2*time.Hour)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if ts.LastRequestPath != "/v1/actions/schedule-downtime" {
+		t.Errorf("unexpected path %s", ts.LastRequestPath)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(ts.LastRequestBody), &payload); err != nil {
+		t.Fatalf("unexpected payload %s", ts.LastRequestBody)
+	}
+	if payload["type"] != "Host" || payload["filter"] != "host.name in hosts" {
+		t.Errorf("expected a host downtime, got %v", payload)
+	}
+	if payload["start_time"] != float64(1700000000) || payload["end_time"] != float64(1700007200) || payload["fixed"] != true {
+		t.Errorf("unexpected downtime window %v", payload)
+	}
+}
+
+func TestClient_Action_Error(t *testing.T) {
+	ts := NewTestIntegrationServer()
+	defer ts.Server.Close()
+
+	client := &Client{
+		httpClient: http.DefaultClient,
+		Hostname:   ts.Server.URL,
+	}
+	err := client.AcknowledgeProblem(ActionTarget{Hosts: []string{"unknown-host"}}, "jdoe", "")
+	httpErr, ok := err.(*HTTPError)
+	if !ok || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 HTTPError, got %v", err)
+	}
+}
@@ -3,36 +3,119 @@ package icinga2apiclient
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 )
 
+// Every host is counted in exactly one of up, down and unreachable, Icinga2
+// counts only reachable hosts as up or down. Pending hosts are also counted
+// as up, until their first check result arrives.
+
+// NumHosts returns the number of hosts.
+func (s *CIBStatus) NumHosts() int {
+	return s.NumHostsUp + s.NumHostsDown + s.NumHostsUnreachable
+}
+
+// NumServices returns the number of services. Unreachable and pending
+// services are also counted in the state they are in.
+func (s *CIBStatus) NumServices() int {
+	return s.NumServicesOk + s.NumServicesWarning + s.NumServicesCritical + s.NumServicesUnknown
+}
+
 func (s *CIBStatus) PercentHostsUp() float32 {
-	sumOfHosts := float32(s.NumHostsUp + s.NumHostsDown)
-	if sumOfHosts == 0 {
-		return 0
-	}
-	return float32(100.0) / sumOfHosts * float32(s.NumHostsUp)
+	return percent(s.NumHostsUp, s.NumHosts())
+}
+
+func (s *CIBStatus) PercentHostsDown() float32 {
+	return percent(s.NumHostsDown, s.NumHosts())
+}
+
+func (s *CIBStatus) PercentHostsUnreachable() float32 {
+	return percent(s.NumHostsUnreachable, s.NumHosts())
+}
+
+func (s *CIBStatus) PercentHostsPending() float32 {
+	return percent(s.NumHostsPending, s.NumHosts())
 }
 
 func (s *CIBStatus) PercentServicesOk() float32 {
-	sumOfServices := float32(s.NumServicesOk + s.NumServicesWarning + s.NumServicesWarning + s.NumServicesUnknown)
-	if sumOfServices == 0 {
+	return percent(s.NumServicesOk, s.NumServices())
+}
+
+func (s *CIBStatus) PercentServicesWarning() float32 {
+	return percent(s.NumServicesWarning, s.NumServices())
+}
+
+func (s *CIBStatus) PercentServicesCritical() float32 {
+	return percent(s.NumServicesCritical, s.NumServices())
+}
+
+func (s *CIBStatus) PercentServicesUnknown() float32 {
+	return percent(s.NumServicesUnknown, s.NumServices())
+}
+
+func (s *CIBStatus) PercentServicesUnreachable() float32 {
+	return percent(s.NumServicesUnreachable, s.NumServices())
+}
+
+func (s *CIBStatus) PercentServicesPending() float32 {
+	return percent(s.NumServicesPending, s.NumServices())
+}
+
+// NumHostsUnhandled returns the number of host problems that are neither
+// acknowledged, in a downtime nor unreachable.
+func (s *CIBStatus) NumHostsUnhandled() int {
+	return max(s.NumHostsProblem-s.NumHostsHandled, 0)
+}
+
+// NumServicesUnhandled returns the number of service problems that are
+// neither acknowledged, in a downtime nor unreachable.
+func (s *CIBStatus) NumServicesUnhandled() int {
+	return max(s.NumServicesProblem-s.NumServicesHandled, 0)
+}
+
+// PercentHostsHandled returns the share of host problems that are handled.
+func (s *CIBStatus) PercentHostsHandled() float32 {
+	return percent(s.NumHostsHandled, s.NumHostsProblem)
+}
+
+// PercentServicesHandled returns the share of service problems that are handled.
+func (s *CIBStatus) PercentServicesHandled() float32 {
+	return percent(s.NumServicesHandled, s.NumServicesProblem)
+}
+
+func percent(part int, total int) float32 {
+	if total == 0 {
 		return 0
 	}
-	return float32(100.0) / sumOfServices * float32(s.NumServicesOk)
+	return float32(100.0) / float32(total) * float32(part)
+}
+
+// RoundPercent rounds a percentage to one decimal place for display. Values
+// between 0 and 100 never round to 0 or 100, so a single host down out of
+// thousands doesn't show as 100% up.
+func RoundPercent(value float32) float32 {
+	rounded := float32(math.Round(float64(value)*10) / 10)
+	switch {
+	case value > 0 && rounded <= 0:
+		return 0.1
+	case value < 100 && rounded >= 100:
+		return 99.9
+	}
+	return rounded
 }
 
 func (client *Client) GetCIBStatus() (*CIBStatus, error) {
 	responseBody, err := client.makeRequest(http.MethodGet, "/v1/status/CIB", nil)
 	if err != nil {
-		fmt.Printf("Error fetching CIB status: %v\n", err)
+		fmt.Fprintf(LogOutput, "Error fetching CIB status: %v\n", err)
 		return nil, err
 	}
 
 	var cibStatusResponseStruct cibStatusResponse
 	err = json.Unmarshal(responseBody, &cibStatusResponseStruct)
 	if err != nil {
-		fmt.Printf("Unable to parse JSON: %v\n", err)
+		fmt.Fprintf(LogOutput, "Unable to parse JSON: %v\n", err)
 		return nil, err
 	}
 
@@ -1,6 +1,7 @@
 package icinga2apiclient
 
 import (
+	"encoding/json"
 	"net/http"
 	"reflect"
 	"testing"
@@ -24,6 +25,32 @@ func TestClient_GetCIBStatus_Integration(t *testing.T) {
 	}
 }
 
+func TestCIBStatus_FullStatus(t *testing.T) {
+	body := `{"results":[{"name":"CIB","perfdata":[],"status":{
+		"active_host_checks":0.35,"active_host_checks_15min":312.0,"active_host_checks_1min":21.0,"active_host_checks_5min":104.0,
+		"active_service_checks":2.4,"active_service_checks_15min":2160.0,"active_service_checks_1min":144.0,"active_service_checks_5min":720.0,
+		"avg_execution_time":1.2,"avg_latency":0.8,"current_concurrent_checks":3.0,"current_pending_callbacks":0.0,
+		"max_execution_time":10.0,"max_latency":2.5,"min_execution_time":0.01,"min_latency":0.0,
+		"num_hosts_acknowledged":1,"num_hosts_down":3,"num_hosts_flapping":0,"num_hosts_handled":2,"num_hosts_in_downtime":1,
+		"num_hosts_pending":4,"num_hosts_problem":3,"num_hosts_unreachable":5,"num_hosts_up":40,
+		"num_services_acknowledged":2,"num_services_critical":6,"num_services_flapping":1,"num_services_handled":4,
+		"num_services_in_downtime":2,"num_services_ok":300,"num_services_pending":7,"num_services_problem":10,
+		"num_services_unknown":1,"num_services_unreachable":8,"num_services_warning":3,
+		"passive_host_checks":0.0,"passive_service_checks":0.1,"remote_check_queue":0.0,"uptime":86400.5}}]}`
+
+	var response cibStatusResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	status := response.Results[0].Status
+	if status.NumHostsUnreachable != 5 || status.NumHostsPending != 4 || status.NumServicesHandled != 4 || status.NumServicesUnreachable != 8 {
+		t.Errorf("unexpected counters: %+v", status)
+	}
+	if status.AvgLatency != 0.8 || status.AvgExecutionTime != 1.2 || status.ActiveServiceChecks1Min != 144 || status.Uptime != 86400.5 {
+		t.Errorf("unexpected check statistics: %+v", status)
+	}
+}
+
 func TestPercentHostsUp(t *testing.T) {
 	status := CIBStatus{NumHostsUp: 5, NumHostsDown: 5}
 	expected := float32(100.0) / float32(10) * float32(5)
@@ -38,8 +65,7 @@ func TestPercentHostsUp(t *testing.T) {
 
 func TestPercentServicesOk(t *testing.T) {
 	status := CIBStatus{NumServicesOk: 5, NumServicesWarning: 2, NumServicesCritical: 1, NumServicesUnknown: 2}
-	sum := float32(5 + 2 + 2 + 2) // Note: NumServicesWarning is counted twice in original code
-	expected := float32(100.0) / sum * float32(5)
+	expected := float32(100.0) / float32(5+2+1+2) * float32(5)
 	if status.PercentServicesOk() != expected {
 		t.Errorf("PercentServicesOk = %v, want %v", status.PercentServicesOk(), expected)
 	}
@@ -48,3 +74,73 @@ func TestPercentServicesOk(t *testing.T) {
 		t.Error("PercentServicesOk should be 0 when no services")
 	}
 }
+
+func TestCIBStatus_Percentages(t *testing.T) {
+	status := CIBStatus{
+		NumHostsUp: 14, NumHostsDown: 4, NumHostsUnreachable: 2, NumHostsPending: 1,
+		NumHostsProblem: 6, NumHostsHandled: 3,
+		NumServicesOk: 60, NumServicesWarning: 20, NumServicesCritical: 15, NumServicesUnknown: 5,
+		NumServicesUnreachable: 10, NumServicesPending: 2, NumServicesProblem: 40, NumServicesHandled: 10,
+	}
+	empty := CIBStatus{}
+
+	tests := []struct {
+		name     string
+		percent  func(*CIBStatus) float32
+		expected float32
+	}{
+		{"hosts up", (*CIBStatus).PercentHostsUp, 70},
+		{"hosts down", (*CIBStatus).PercentHostsDown, 20},
+		{"hosts unreachable", (*CIBStatus).PercentHostsUnreachable, 10},
+		{"hosts pending", (*CIBStatus).PercentHostsPending, 5},
+		{"services ok", (*CIBStatus).PercentServicesOk, 60},
+		{"services warning", (*CIBStatus).PercentServicesWarning, 20},
+		{"services critical", (*CIBStatus).PercentServicesCritical, 15},
+		{"services unknown", (*CIBStatus).PercentServicesUnknown, 5},
+		{"services unreachable", (*CIBStatus).PercentServicesUnreachable, 10},
+		{"services pending", (*CIBStatus).PercentServicesPending, 2},
+		{"host problems handled", (*CIBStatus).PercentHostsHandled, 50},
+		{"service problems handled", (*CIBStatus).PercentServicesHandled, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.percent(&status); got != tt.expected {
+				t.Errorf("got %v, want %v", got, tt.expected)
+			}
+			if got := tt.percent(&empty); got != 0 {
+				t.Errorf("got %v without hosts and services, want 0", got)
+			}
+		})
+	}
+
+	if status.NumHosts() != 20 || status.NumServices() != 100 {
+		t.Errorf("unexpected totals %d and %d", status.NumHosts(), status.NumServices())
+	}
+	if status.NumHostsUnhandled() != 3 || status.NumServicesUnhandled() != 30 {
+		t.Errorf("unexpected unhandled problems %d and %d", status.NumHostsUnhandled(), status.NumServicesUnhandled())
+	}
+}
+
+func TestRoundPercent(t *testing.T) {
+	tests := []struct {
+		value    float32
+		expected float32
+	}{
+		{0, 0},
+		{100, 100},
+		{66.66667, 66.7},
+		{33.33333, 33.3},
+		{12.25, 12.3},
+		// Almost everything up is not everything up
+		{99.96, 99.9},
+		{99.999, 99.9},
+		// A single problem is not no problem
+		{0.04, 0.1},
+		{0.001, 0.1},
+	}
+	for _, tt := range tests {
+		if got := RoundPercent(tt.value); got != tt.expected {
+			t.Errorf("RoundPercent(%v) = %v, want %v", tt.value, got, tt.expected)
+		}
+	}
+}
@@ -11,6 +11,10 @@ import (
 	"time"
 )
 
+// LogOutput receives the errors of failed requests. A terminal UI sets it to
+// io.Discard, so log lines don't scroll its screen.
+var LogOutput io.Writer = os.Stdout
+
 func NewClient(hostName string, certFile string, keyFile string, caCertFile string, timeOutSecs int, verifyCertificate bool) (*Client, error) {
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: !verifyCertificate,
@@ -20,7 +24,7 @@ func NewClient(hostName string, certFile string, keyFile string, caCertFile stri
 		// Load client certificate
 		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
 		if err != nil {
-			fmt.Printf("Error loading client certificate: %v\n", err)
+			fmt.Fprintf(LogOutput, "Error loading client certificate: %v\n", err)
 			return nil, err
 		}
 
@@ -31,7 +35,7 @@ func NewClient(hostName string, certFile string, keyFile string, caCertFile stri
 		// Load CA certificate
 		caCert, err := os.ReadFile(caCertFile)
 		if err != nil {
-			fmt.Printf("Error reading CA certificate: %v\n", err)
+			fmt.Fprintf(LogOutput, "Error reading CA certificate: %v\n", err)
 			return nil, err
 		}
 
@@ -57,22 +61,34 @@ func NewClient(hostName string, certFile string, keyFile string, caCertFile stri
 }
 
 func (client *Client) makeRequest(verb string, path string, payload []byte) ([]byte, error) {
+	req, err := client.newRequest(verb, path, payload)
+	if err != nil {
+		return nil, err
+	}
+	// Icinga wants a GET, but GET requests can't contain a payload
+	req.Header.Set("X-HTTP-Method-Override", "GET")
+
+	return client.doRequest(req)
+}
+
+func (client *Client) newRequest(verb string, path string, payload []byte) (*http.Request, error) {
 	url := client.Hostname + path
 	req, err := http.NewRequest(verb, url, bytes.NewBuffer(payload))
 	if err != nil {
-		fmt.Printf("Error creating request: %v\n", err)
+		fmt.Fprintf(LogOutput, "Error creating request: %v\n", err)
 		return nil, err
 	}
 
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
-	// Icinga wants a GET, but GET requests can't contain a payload
-	req.Header.Set("X-HTTP-Method-Override", "GET")
 
 	if client.Username != "" && client.Password != "" {
 		req.SetBasicAuth(client.Username, client.Password)
 	}
+	return req, nil
+}
 
+func (client *Client) doRequest(req *http.Request) ([]byte, error) {
 	resp, err := client.httpClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,103 @@
+package icinga2apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// GetAPIListenerStatus returns the cluster connections as seen by the node the client talks to.
+func (client *Client) GetAPIListenerStatus() (*APIListenerStatus, error) {
+	responseBody, err := client.makeRequest(http.MethodGet, "/v1/status/ApiListener", nil)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error fetching ApiListener status: %v\n", err)
+		return nil, err
+	}
+
+	var responseStruct apiListenerStatusResponse
+	err = json.Unmarshal(responseBody, &responseStruct)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Unable to parse JSON: %v\n", err)
+		return nil, err
+	}
+
+	if len(responseStruct.Results) == 0 {
+		return nil, fmt.Errorf("No results in ApiListener response")
+	}
+
+	status := responseStruct.Results[0].Status.API
+	return &status, nil
+}
+
+func (client *Client) GetEndpoints() ([]Endpoint, error) {
+	payload := requestPayload{
+		Attributes: []string{"name", "connected", "last_message_received"},
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error marshaling JSON payload: %v\n", err)
+		return nil, err
+	}
+
+	responseBody, err := client.makeRequest(http.MethodPost, "/v1/objects/endpoints", jsonPayload)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error fetching endpoints: %v\n", err)
+		return nil, err
+	}
+
+	var responseStruct getEndpointsResponse
+	err = json.Unmarshal(responseBody, &responseStruct)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Unable to parse JSON: %v\n", err)
+		return nil, err
+	}
+
+	var endpoints []Endpoint
+	for _, endpointJSON := range responseStruct.Results {
+		endpoints = append(endpoints, Endpoint{
+			Name:                endpointJSON.Attributes.Name,
+			Connected:           endpointJSON.Attributes.Connected,
+			LastMessageReceived: unixToTime(endpointJSON.Attributes.LastMessageReceived),
+		})
+	}
+
+	return endpoints, nil
+}
+
+func (client *Client) GetZones() ([]Zone, error) {
+	payload := requestPayload{
+		Attributes: []string{"name", "endpoints", "parent", "global"},
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error marshaling JSON payload: %v\n", err)
+		return nil, err
+	}
+
+	responseBody, err := client.makeRequest(http.MethodPost, "/v1/objects/zones", jsonPayload)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error fetching zones: %v\n", err)
+		return nil, err
+	}
+
+	var responseStruct getZonesResponse
+	err = json.Unmarshal(responseBody, &responseStruct)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Unable to parse JSON: %v\n", err)
+		return nil, err
+	}
+
+	var zones []Zone
+	for _, zoneJSON := range responseStruct.Results {
+		zones = append(zones, Zone{
+			Name:      zoneJSON.Attributes.Name,
+			Endpoints: zoneJSON.Attributes.Endpoints,
+			Parent:    zoneJSON.Attributes.Parent,
+			Global:    zoneJSON.Attributes.Global,
+		})
+	}
+
+	return zones, nil
+}
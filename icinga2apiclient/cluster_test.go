@@ -0,0 +1,71 @@
+package icinga2apiclient
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_GetAPIListenerStatus_Integration(t *testing.T) {
+	ts := NewTestIntegrationServer()
+	defer ts.Server.Close()
+
+	client := &Client{
+		httpClient: http.DefaultClient,
+		Hostname:   ts.Server.URL,
+	}
+	status, err := client.GetAPIListenerStatus()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.Identity != "master1" || status.NumNotConnEndpoints != 1 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	expected := APIListenerZone{ClientLogLag: 42.5, Connected: true, Endpoints: []string{"satellite1", "satellite2"}, ParentZone: "master"}
+	if !reflect.DeepEqual(status.Zones["satellite"], expected) {
+		t.Errorf("unexpected zone: got %+v, want %+v", status.Zones["satellite"], expected)
+	}
+}
+
+func TestClient_GetEndpoints_Integration(t *testing.T) {
+	ts := NewTestIntegrationServer()
+	defer ts.Server.Close()
+
+	client := &Client{
+		httpClient: http.DefaultClient,
+		Hostname:   ts.Server.URL,
+	}
+	endpoints, err := client.GetEndpoints()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []Endpoint{
+		{Name: "satellite1", Connected: true, LastMessageReceived: time.Unix(1700000000, 0)},
+		{Name: "satellite2", Connected: false},
+	}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("unexpected endpoints: got %+v, want %+v", endpoints, expected)
+	}
+}
+
+func TestClient_GetZones_Integration(t *testing.T) {
+	ts := NewTestIntegrationServer()
+	defer ts.Server.Close()
+
+	client := &Client{
+		httpClient: http.DefaultClient,
+		Hostname:   ts.Server.URL,
+	}
+	zones, err := client.GetZones()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []Zone{
+		{Name: "satellite", Endpoints: []string{"satellite1", "satellite2"}, Parent: "master"},
+		{Name: "global-templates", Endpoints: []string{}, Global: true},
+	}
+	if !reflect.DeepEqual(zones, expected) {
+		t.Errorf("unexpected zones: got %+v, want %+v", zones, expected)
+	}
+}
@@ -0,0 +1,48 @@
+package icinga2apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func (client *Client) GetDependencies() ([]Dependency, error) {
+	payload := requestPayload{
+		Attributes: []string{"child_host_name", "child_service_name", "parent_host_name", "parent_service_name"},
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error marshaling JSON payload: %v\n", err)
+		return nil, err
+	}
+
+	responseBody, err := client.makeRequest(http.MethodPost, "/v1/objects/dependencies", jsonPayload)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error fetching dependencies: %v\n", err)
+		return nil, err
+	}
+
+	var responseStruct getDependenciesResponse
+	err = json.Unmarshal(responseBody, &responseStruct)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Unable to parse JSON: %v\n", err)
+		return nil, err
+	}
+
+	var dependencies []Dependency
+	for _, dependencyJSON := range responseStruct.Results {
+		dependencies = append(dependencies, NewDependencyFromJSON(dependencyJSON))
+	}
+
+	return dependencies, nil
+}
+
+func NewDependencyFromJSON(dependencyJSON icinga2dependencyJSON) Dependency {
+	return Dependency{
+		ChildHostName:     dependencyJSON.Attributes.ChildHostName,
+		ChildServiceName:  dependencyJSON.Attributes.ChildServiceName,
+		ParentHostName:    dependencyJSON.Attributes.ParentHostName,
+		ParentServiceName: dependencyJSON.Attributes.ParentServiceName,
+	}
+}
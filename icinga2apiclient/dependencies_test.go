@@ -0,0 +1,26 @@
+package icinga2apiclient
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClient_GetDependencies_Integration(t *testing.T) {
+	ts := NewTestIntegrationServer()
+	defer ts.Server.Close()
+
+	client := &Client{
+		httpClient: http.DefaultClient,
+		Hostname:   ts.Server.URL,
+	}
+	dependencies, err := client.GetDependencies()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(dependencies) != 1 {
+		t.Fatalf("expected 1 dependency, got %d", len(dependencies))
+	}
+	if dependencies[0].ChildHostName != "host1" || dependencies[0].ParentHostName != "switch1" {
+		t.Errorf("unexpected dependency: %+v", dependencies[0])
+	}
+}
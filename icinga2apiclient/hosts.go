@@ -8,26 +8,26 @@ import (
 
 func (client *Client) GetHosts(minStateType int) ([]Host, error) {
 	payload := requestPayload{
-		Attributes: []string{"name", "state", "state_type", "downtime_depth", "acknowledgement", "vars"},
+		Attributes: []string{"name", "state", "state_type", "downtime_depth", "acknowledgement", "last_reachable", "last_state_change", "groups", "vars"},
 		Filters:    fmt.Sprintf("host.state != 0 && host.downtime_depth == 0 && host.acknowledgement == 0 && host.state_type >= %d", minStateType),
 	}
 
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
-		fmt.Printf("Error marshaling JSON payload: %v\n", err)
+		fmt.Fprintf(LogOutput, "Error marshaling JSON payload: %v\n", err)
 		return nil, err
 	}
 
 	responseBody, err := client.makeRequest(http.MethodPost, "/v1/objects/hosts", jsonPayload)
 	if err != nil {
-		fmt.Printf("Error fetching hosts: %v\n", err)
+		fmt.Fprintf(LogOutput, "Error fetching hosts: %v\n", err)
 		return nil, err
 	}
 
 	var responseStruct getHostsResponse
 	err = json.Unmarshal(responseBody, &responseStruct)
 	if err != nil {
-		fmt.Printf("Unable to parse JSON: %v\n", err)
+		fmt.Fprintf(LogOutput, "Unable to parse JSON: %v\n", err)
 		return nil, err
 	}
 
@@ -41,9 +41,18 @@ func (client *Client) GetHosts(minStateType int) ([]Host, error) {
 
 func NewHostFromJSON(hostJSON icinga2hostJSON) Host {
 	host := Host{
-		Name:      hostJSON.Name,
-		State:     hostJSON.Attributes.State,
-		StateType: hostJSON.Attributes.StateType,
+		Name:            hostJSON.Name,
+		State:           hostJSON.Attributes.State,
+		StateType:       hostJSON.Attributes.StateType,
+		Groups:          hostJSON.Attributes.Groups,
+		Vars:            hostJSON.Attributes.Vars,
+		LastStateChange: unixToTime(hostJSON.Attributes.LastStateChange),
+		LastCheck:       unixToTime(hostJSON.Attributes.LastCheck),
+		CheckInterval:   secondsToDuration(hostJSON.Attributes.CheckInterval),
+	}
+	// Icinga only reports last_reachable when asked for it, treat a missing value as reachable
+	if hostJSON.Attributes.LastReachable != nil {
+		host.Unreachable = !*hostJSON.Attributes.LastReachable
 	}
 	return host
 }
@@ -20,9 +20,15 @@ func TestClient_GetHosts_Integration(t *testing.T) {
 	if len(hosts) != 1 {
 		t.Fatalf("expected 1 host, got %d", len(hosts))
 	}
-	if hosts[0].Name != "host1" || hosts[0].State != 1 || hosts[0].StateType != 0 {
+	if hosts[0].Name != "host1" || hosts[0].State != 1 || hosts[0].StateType != 0 || !hosts[0].Unreachable {
 		t.Errorf("unexpected host: %+v", hosts[0])
 	}
+	if len(hosts[0].Groups) != 1 || hosts[0].Groups[0] != "linux-servers" {
+		t.Errorf("unexpected groups: %v", hosts[0].Groups)
+	}
+	if hosts[0].LastStateChange.Unix() != 1700000000 {
+		t.Errorf("unexpected last state change: %v", hosts[0].LastStateChange)
+	}
 }
 
 func TestNewHostFromJSON(t *testing.T) {
@@ -42,3 +48,24 @@ func TestNewHostFromJSON(t *testing.T) {
 		t.Errorf("NewHostFromJSON failed: got State=%v, StateType=%v", host.State, host.StateType)
 	}
 }
+
+func TestNewHostFromJSON_LastReachable(t *testing.T) {
+	reachable := true
+	json := icinga2hostJSON{
+		Attributes: icinga2HostAttributesJSON{LastReachable: &reachable},
+		Name:       "host1",
+	}
+	if NewHostFromJSON(json).Unreachable {
+		t.Errorf("expected host with last_reachable=true to be reachable")
+	}
+
+	reachable = false
+	if !NewHostFromJSON(json).Unreachable {
+		t.Errorf("expected host with last_reachable=false to be unreachable")
+	}
+
+	json.Attributes.LastReachable = nil
+	if NewHostFromJSON(json).Unreachable {
+		t.Errorf("expected host without last_reachable to be reachable")
+	}
+}
@@ -9,26 +9,27 @@ import (
 
 func (client *Client) GetServices(minState int, maxState int, minStateType int) ([]Service, error) {
 	payload := requestPayload{
-		Attributes: []string{"name", "state", "state_type", "downtime_depth", "acknowledgement", "vars", "display_name"},
+		Attributes: []string{"name", "state", "state_type", "downtime_depth", "acknowledgement", "last_state_change", "vars", "display_name"},
+		Joins:      []string{"host.groups"},
 		Filters:    fmt.Sprintf("service.state >= %d && service.state <= %d && service.state_type >= %d && service.acknowledgement == 0 && service.downtime_depth == 0 && host.state == 0", minState, maxState, minStateType),
 	}
 
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
-		fmt.Printf("Error marshaling JSON payload: %v\n", err)
+		fmt.Fprintf(LogOutput, "Error marshaling JSON payload: %v\n", err)
 		return nil, err
 	}
 
 	responseBody, err := client.makeRequest(http.MethodPost, "/v1/objects/services", jsonPayload)
 	if err != nil {
-		fmt.Printf("Error fetching services: %v\n", err)
+		fmt.Fprintf(LogOutput, "Error fetching services: %v\n", err)
 		return nil, err
 	}
 
 	var responseStruct getServiceResponse
 	err = json.Unmarshal(responseBody, &responseStruct)
 	if err != nil {
-		fmt.Printf("Unable to parse JSON: %v\n", err)
+		fmt.Fprintf(LogOutput, "Unable to parse JSON: %v\n", err)
 		return nil, err
 	}
 
@@ -42,8 +43,13 @@ func (client *Client) GetServices(minState int, maxState int, minStateType int)
 
 func NewServiceFromJSON(serviceJSON icinga2serviceJSON) Service {
 	service := Service{
-		State:     serviceJSON.Attributes.State,
-		StateType: serviceJSON.Attributes.StateType,
+		State:           serviceJSON.Attributes.State,
+		StateType:       serviceJSON.Attributes.StateType,
+		HostGroups:      serviceJSON.Joins.Host.Groups,
+		Vars:            serviceJSON.Attributes.Vars,
+		LastStateChange: unixToTime(serviceJSON.Attributes.LastStateChange),
+		LastCheck:       unixToTime(serviceJSON.Attributes.LastCheck),
+		CheckInterval:   secondsToDuration(serviceJSON.Attributes.CheckInterval),
 	}
 	// Split the Name into Hostname and Service name
 	parts := strings.SplitN(serviceJSON.Name, "!", 2) // Split into at most 2 parts
@@ -23,6 +23,15 @@ func TestClient_GetServices_Integration(t *testing.T) {
 	if services[0].HostName != "host1" || services[0].ServiceName != "service1" || services[0].State != 2 || services[0].StateType != 1 {
 		t.Errorf("unexpected service: %+v", services[0])
 	}
+	if len(services[0].HostGroups) != 1 || services[0].HostGroups[0] != "linux-servers" {
+		t.Errorf("unexpected host groups: %v", services[0].HostGroups)
+	}
+	if services[0].LastStateChange.UnixMilli() != 1700000000500 {
+		t.Errorf("unexpected last state change: %v", services[0].LastStateChange)
+	}
+	if services[0].Vars["team"] != "ops" {
+		t.Errorf("unexpected vars: %v", services[0].Vars)
+	}
 }
 
 func TestNewServiceFromJSON(t *testing.T) {
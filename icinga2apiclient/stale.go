@@ -0,0 +1,79 @@
+package icinga2apiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// GetStaleServices returns actively checked services whose last check is older
+// than factor times their check interval. Services that were never checked
+// and services in a downtime are left out.
+func (client *Client) GetStaleServices(factor int) ([]Service, error) {
+	payload := requestPayload{
+		Attributes: []string{"name", "state", "state_type", "last_check", "check_interval", "last_state_change", "vars"},
+		Joins:      []string{"host.groups"},
+		Filters:    fmt.Sprintf("service.enable_active_checks && service.downtime_depth == 0 && service.last_check > 0 && service.last_check < get_time() - service.check_interval * %d", factor),
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error marshaling JSON payload: %v\n", err)
+		return nil, err
+	}
+
+	responseBody, err := client.makeRequest(http.MethodPost, "/v1/objects/services", jsonPayload)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error fetching stale services: %v\n", err)
+		return nil, err
+	}
+
+	var responseStruct getServiceResponse
+	err = json.Unmarshal(responseBody, &responseStruct)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Unable to parse JSON: %v\n", err)
+		return nil, err
+	}
+
+	var services []Service
+	for _, serviceJSON := range responseStruct.Results {
+		services = append(services, NewServiceFromJSON(serviceJSON))
+	}
+
+	return services, nil
+}
+
+// GetStaleHosts returns actively checked hosts whose last check is older
+// than factor times their check interval, see GetStaleServices.
+func (client *Client) GetStaleHosts(factor int) ([]Host, error) {
+	payload := requestPayload{
+		Attributes: []string{"name", "state", "state_type", "last_check", "check_interval", "last_state_change", "groups", "vars"},
+		Filters:    fmt.Sprintf("host.enable_active_checks && host.downtime_depth == 0 && host.last_check > 0 && host.last_check < get_time() - host.check_interval * %d", factor),
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error marshaling JSON payload: %v\n", err)
+		return nil, err
+	}
+
+	responseBody, err := client.makeRequest(http.MethodPost, "/v1/objects/hosts", jsonPayload)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Error fetching stale hosts: %v\n", err)
+		return nil, err
+	}
+
+	var responseStruct getHostsResponse
+	err = json.Unmarshal(responseBody, &responseStruct)
+	if err != nil {
+		fmt.Fprintf(LogOutput, "Unable to parse JSON: %v\n", err)
+		return nil, err
+	}
+
+	var hosts []Host
+	for _, hostJSON := range responseStruct.Results {
+		hosts = append(hosts, NewHostFromJSON(hostJSON))
+	}
+
+	return hosts, nil
+}
@@ -0,0 +1,54 @@
+package icinga2apiclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_GetStaleServices_Integration(t *testing.T) {
+	ts := NewTestIntegrationServer()
+	defer ts.Server.Close()
+
+	client := &Client{
+		httpClient: http.DefaultClient,
+		Hostname:   ts.Server.URL,
+	}
+	services, err := client.GetStaleServices(3)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(ts.LastRequestBody, "get_time() - service.check_interval * 3") {
+		t.Errorf("expected a stale filter, got %s", ts.LastRequestBody)
+	}
+	if len(services) != 1 {
+		t.Fatalf("expected 1 service, got %d", len(services))
+	}
+	if !services[0].LastCheck.Equal(time.Unix(1700000300, 0)) || services[0].CheckInterval != time.Minute {
+		t.Errorf("unexpected last check or interval: %+v", services[0])
+	}
+}
+
+func TestClient_GetStaleHosts_Integration(t *testing.T) {
+	ts := NewTestIntegrationServer()
+	defer ts.Server.Close()
+
+	client := &Client{
+		httpClient: http.DefaultClient,
+		Hostname:   ts.Server.URL,
+	}
+	hosts, err := client.GetStaleHosts(2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(ts.LastRequestBody, "get_time() - host.check_interval * 2") {
+		t.Errorf("expected a stale filter, got %s", ts.LastRequestBody)
+	}
+	if len(hosts) != 1 {
+		t.Fatalf("expected 1 host, got %d", len(hosts))
+	}
+	if !hosts[0].LastCheck.Equal(time.Unix(1700000600, 0)) || hosts[0].CheckInterval != 300500*time.Millisecond {
+		t.Errorf("unexpected last check or interval: %+v", hosts[0])
+	}
+}
@@ -1,6 +1,7 @@
 package icinga2apiclient
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -15,9 +16,19 @@ func NewTestIntegrationServer() *TestIntegrationServer {
 
 type TestIntegrationServer struct {
 	Server *httptest.Server
+	// Body of the last request, e.g. to check the filter sent
+	LastRequestBody string
+	// Path and X-HTTP-Method-Override header of the last request
+	LastRequestPath    string
+	LastMethodOverride string
 }
 
 func (ts *TestIntegrationServer) handler(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	ts.LastRequestBody = string(body)
+	ts.LastRequestPath = r.URL.Path
+	ts.LastMethodOverride = r.Header.Get("X-HTTP-Method-Override")
+
 	switch {
 	case strings.HasPrefix(r.URL.Path, "/v1/status/IcingaApplication"):
 		w.Header().Set("Content-Type", "application/json")
@@ -26,15 +37,40 @@ func (ts *TestIntegrationServer) handler(w http.ResponseWriter, r *http.Request)
 	case strings.HasPrefix(r.URL.Path, "/v1/objects/services"):
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"results":[{"attrs":{"state":2,"state_type":1},"name":"host1!service1","type":"Service"}]}`))
+		w.Write([]byte(`{"results":[{"attrs":{"check_interval":60,"last_check":1700000300,"last_state_change":1700000000.5,"state":2,"state_type":1,"vars":{"team":"ops"}},"joins":{"host":{"groups":["linux-servers"]}},"name":"host1!service1","type":"Service"}]}`))
 	case strings.HasPrefix(r.URL.Path, "/v1/objects/hosts"):
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"results":[{"attrs":{"acknowledgement":0,"name":"host1","state":1,"state_type":0,"vars":{}},"name":"host1","type":"Host"}]}`))
+		w.Write([]byte(`{"results":[{"attrs":{"acknowledgement":0,"check_interval":300.5,"last_check":1700000600,"last_reachable":false,"last_state_change":1700000000,"groups":["linux-servers"],"name":"host1","state":1,"state_type":0,"vars":{}},"name":"host1","type":"Host"}]}`))
+	case strings.HasPrefix(r.URL.Path, "/v1/objects/dependencies"):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"results":[{"attrs":{"child_host_name":"host1","child_service_name":"","parent_host_name":"switch1","parent_service_name":""},"name":"host1!switch1","type":"Dependency"}]}`))
 	case strings.HasPrefix(r.URL.Path, "/v1/status/CIB"):
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"results":[{"status":{"num_hosts_up":2,"num_hosts_down":1,"num_services_ok":5,"num_services_warning":1,"num_services_critical":0,"num_services_unknown":0}}]}`))
+	case strings.HasPrefix(r.URL.Path, "/v1/status/ApiListener"):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"results":[{"name":"ApiListener","status":{"api":{"identity":"master1","num_endpoints":2,"num_conn_endpoints":1,"num_not_conn_endpoints":1,"conn_endpoints":["satellite1"],"not_conn_endpoints":["satellite2"],"zones":{"master":{"client_log_lag":0,"connected":true,"endpoints":["master1"],"parent_zone":""},"satellite":{"client_log_lag":42.5,"connected":true,"endpoints":["satellite1","satellite2"],"parent_zone":"master"}}}}}]}`))
+	case strings.HasPrefix(r.URL.Path, "/v1/objects/endpoints"):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"results":[{"attrs":{"connected":true,"last_message_received":1700000000,"name":"satellite1"},"name":"satellite1","type":"Endpoint"},{"attrs":{"connected":false,"last_message_received":0,"name":"satellite2"},"name":"satellite2","type":"Endpoint"}]}`))
+	case strings.HasPrefix(r.URL.Path, "/v1/objects/zones"):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"results":[{"attrs":{"endpoints":["satellite1","satellite2"],"global":false,"name":"satellite","parent":"master"},"name":"satellite","type":"Zone"},{"attrs":{"endpoints":[],"global":true,"name":"global-templates","parent":""},"name":"global-templates","type":"Zone"}]}`))
+	case strings.HasPrefix(r.URL.Path, "/v1/actions/"):
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(ts.LastRequestBody, "unknown-host") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":404,"status":"No objects found."}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"results":[{"code":200,"status":"Successfully processed action for object 'host1!service1'."}]}`))
 	default:
 		w.WriteHeader(http.StatusNotFound)
 		w.Write([]byte(`{"error":"not found"}`))
@@ -3,6 +3,7 @@ package icinga2apiclient
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type Client struct {
@@ -18,7 +19,7 @@ type Client struct {
 type requestPayload struct {
 	Attributes []string `json:"attrs"`
 	Joins      []string `json:"joins"`
-	Filters    string   `json:"filter"`
+	Filters    string   `json:"filter,omitempty"`
 }
 
 type getServiceResponse struct {
@@ -33,8 +34,11 @@ type getHostsResponse struct {
 // "state_type":1,"vars":{"oncall":"plaser"}},"joins":{},"meta":{},"name":"keepalived-1.graylog-coresec.ams1!disk-timeouts","type":"Service"}
 type icinga2ServiceAttributesJSON struct {
 	Acknowledgement int                    `json:"acknowledgement"`
+	CheckInterval   float64                `json:"check_interval"`
 	DisplayName     string                 `json:"display_name"`
 	DowntimeDepth   int                    `json:"downtime_depth"`
+	LastCheck       float64                `json:"last_check"`
+	LastStateChange float64                `json:"last_state_change"`
 	Name            string                 `json:"name"`
 	State           int                    `json:"state"`
 	StateType       int                    `json:"state_type"`
@@ -43,14 +47,26 @@ type icinga2ServiceAttributesJSON struct {
 
 type icinga2HostAttributesJSON struct {
 	Acknowledgement int                    `json:"acknowledgement"`
+	CheckInterval   float64                `json:"check_interval"`
+	Groups          []string               `json:"groups"`
+	LastCheck       float64                `json:"last_check"`
+	LastReachable   *bool                  `json:"last_reachable"`
+	LastStateChange float64                `json:"last_state_change"`
 	Name            string                 `json:"name"`
 	State           int                    `json:"state"`
 	StateType       int                    `json:"state_type"`
 	Vars            map[string]interface{} `json:"vars"`
 }
 
+type icinga2ServiceJoinsJSON struct {
+	Host struct {
+		Groups []string `json:"groups"`
+	} `json:"host"`
+}
+
 type icinga2serviceJSON struct {
 	Attributes icinga2ServiceAttributesJSON `json:"attrs"`
+	Joins      icinga2ServiceJoinsJSON      `json:"joins"`
 	Name       string                       `json:"name"`
 	Type       string                       `json:"type"`
 }
@@ -61,6 +77,23 @@ type icinga2hostJSON struct {
 	Type       string                    `json:"type"`
 }
 
+type getDependenciesResponse struct {
+	Results []icinga2dependencyJSON `json:"results"`
+}
+
+type icinga2DependencyAttributesJSON struct {
+	ChildHostName     string `json:"child_host_name"`
+	ChildServiceName  string `json:"child_service_name"`
+	ParentHostName    string `json:"parent_host_name"`
+	ParentServiceName string `json:"parent_service_name"`
+}
+
+type icinga2dependencyJSON struct {
+	Attributes icinga2DependencyAttributesJSON `json:"attrs"`
+	Name       string                          `json:"name"`
+	Type       string                          `json:"type"`
+}
+
 type cibStatusResponse struct {
 	Results []cIBStatusResult `json:"results"`
 }
@@ -70,24 +103,201 @@ type cIBStatusResult struct {
 }
 
 type CIBStatus struct {
-	NumHostsUp          int `json:"num_hosts_up"`
-	NumHostsDown        int `json:"num_hosts_down"`
-	NumServicesOk       int `json:"num_services_ok"`
-	NumServicesWarning  int `json:"num_services_warning"`
-	NumServicesCritical int `json:"num_services_critical"`
-	NumServicesUnknown  int `json:"num_services_unknown"`
+	NumHostsUp           int `json:"num_hosts_up"`
+	NumHostsDown         int `json:"num_hosts_down"`
+	NumHostsUnreachable  int `json:"num_hosts_unreachable"`
+	NumHostsPending      int `json:"num_hosts_pending"`
+	NumHostsFlapping     int `json:"num_hosts_flapping"`
+	NumHostsInDowntime   int `json:"num_hosts_in_downtime"`
+	NumHostsAcknowledged int `json:"num_hosts_acknowledged"`
+	NumHostsHandled      int `json:"num_hosts_handled"`
+	NumHostsProblem      int `json:"num_hosts_problem"`
+
+	NumServicesOk           int `json:"num_services_ok"`
+	NumServicesWarning      int `json:"num_services_warning"`
+	NumServicesCritical     int `json:"num_services_critical"`
+	NumServicesUnknown      int `json:"num_services_unknown"`
+	NumServicesUnreachable  int `json:"num_services_unreachable"`
+	NumServicesPending      int `json:"num_services_pending"`
+	NumServicesFlapping     int `json:"num_services_flapping"`
+	NumServicesInDowntime   int `json:"num_services_in_downtime"`
+	NumServicesAcknowledged int `json:"num_services_acknowledged"`
+	NumServicesHandled      int `json:"num_services_handled"`
+	NumServicesProblem      int `json:"num_services_problem"`
+
+	// Seconds
+	AvgLatency       float64 `json:"avg_latency"`
+	MinLatency       float64 `json:"min_latency"`
+	MaxLatency       float64 `json:"max_latency"`
+	AvgExecutionTime float64 `json:"avg_execution_time"`
+	MinExecutionTime float64 `json:"min_execution_time"`
+	MaxExecutionTime float64 `json:"max_execution_time"`
+
+	// Checks per second, averaged over the last minute
+	ActiveHostChecks     float64 `json:"active_host_checks"`
+	ActiveServiceChecks  float64 `json:"active_service_checks"`
+	PassiveHostChecks    float64 `json:"passive_host_checks"`
+	PassiveServiceChecks float64 `json:"passive_service_checks"`
+
+	// Checks within the last 1, 5 and 15 minutes. Icinga2 reports them as floating point numbers.
+	ActiveHostChecks1Min      float64 `json:"active_host_checks_1min"`
+	ActiveHostChecks5Min      float64 `json:"active_host_checks_5min"`
+	ActiveHostChecks15Min     float64 `json:"active_host_checks_15min"`
+	ActiveServiceChecks1Min   float64 `json:"active_service_checks_1min"`
+	ActiveServiceChecks5Min   float64 `json:"active_service_checks_5min"`
+	ActiveServiceChecks15Min  float64 `json:"active_service_checks_15min"`
+	PassiveHostChecks1Min     float64 `json:"passive_host_checks_1min"`
+	PassiveHostChecks5Min     float64 `json:"passive_host_checks_5min"`
+	PassiveHostChecks15Min    float64 `json:"passive_host_checks_15min"`
+	PassiveServiceChecks1Min  float64 `json:"passive_service_checks_1min"`
+	PassiveServiceChecks5Min  float64 `json:"passive_service_checks_5min"`
+	PassiveServiceChecks15Min float64 `json:"passive_service_checks_15min"`
+
+	CurrentConcurrentChecks float64 `json:"current_concurrent_checks"`
+	CurrentPendingCallbacks float64 `json:"current_pending_callbacks"`
+	RemoteCheckQueue        float64 `json:"remote_check_queue"`
+	// Seconds since Icinga2 started
+	Uptime float64 `json:"uptime"`
+}
+
+// {"results":[{"name":"ApiListener","status":{"api":{"identity":"master1","num_conn_endpoints":1,...,
+// "zones":{"satellite":{"client_log_lag":0,"connected":true,"endpoints":["satellite1"],"parent_zone":"master"}}}}}]}
+type apiListenerStatusResponse struct {
+	Results []struct {
+		Name   string `json:"name"`
+		Status struct {
+			API APIListenerStatus `json:"api"`
+		} `json:"status"`
+	} `json:"results"`
+}
+
+type APIListenerStatus struct {
+	// Name of the local endpoint
+	Identity            string                     `json:"identity"`
+	NumEndpoints        int                        `json:"num_endpoints"`
+	NumConnEndpoints    int                        `json:"num_conn_endpoints"`
+	NumNotConnEndpoints int                        `json:"num_not_conn_endpoints"`
+	ConnEndpoints       []string                   `json:"conn_endpoints"`
+	NotConnEndpoints    []string                   `json:"not_conn_endpoints"`
+	Zones               map[string]APIListenerZone `json:"zones"`
+}
+
+type APIListenerZone struct {
+	// Seconds the replay log of the zone lags behind
+	ClientLogLag float64  `json:"client_log_lag"`
+	Connected    bool     `json:"connected"`
+	Endpoints    []string `json:"endpoints"`
+	ParentZone   string   `json:"parent_zone"`
+}
+
+type getEndpointsResponse struct {
+	Results []icinga2endpointJSON `json:"results"`
+}
+
+type icinga2EndpointAttributesJSON struct {
+	Connected           bool    `json:"connected"`
+	LastMessageReceived float64 `json:"last_message_received"`
+	Name                string  `json:"name"`
+}
+
+type icinga2endpointJSON struct {
+	Attributes icinga2EndpointAttributesJSON `json:"attrs"`
+	Name       string                        `json:"name"`
+	Type       string                        `json:"type"`
+}
+
+type getZonesResponse struct {
+	Results []icinga2zoneJSON `json:"results"`
+}
+
+type icinga2ZoneAttributesJSON struct {
+	Endpoints []string `json:"endpoints"`
+	Global    bool     `json:"global"`
+	Name      string   `json:"name"`
+	Parent    string   `json:"parent"`
+}
+
+type icinga2zoneJSON struct {
+	Attributes icinga2ZoneAttributesJSON `json:"attrs"`
+	Name       string                    `json:"name"`
+	Type       string                    `json:"type"`
 }
+
 type Service struct {
-	HostName    string
-	ServiceName string
-	State       int
-	StateType   int
+	HostName        string
+	ServiceName     string
+	State           int
+	StateType       int
+	HostGroups      []string
+	Vars            map[string]interface{}
+	LastStateChange time.Time
+	// Only set by GetStaleServices
+	LastCheck     time.Time
+	CheckInterval time.Duration
 }
 
 type Host struct {
+	Name            string
+	State           int
+	StateType       int
+	Unreachable     bool
+	Groups          []string
+	Vars            map[string]interface{}
+	LastStateChange time.Time
+	// Only set by GetStaleHosts
+	LastCheck     time.Time
+	CheckInterval time.Duration
+}
+
+// Dependency describes a parent/child relationship between two checkables.
+// The service names are empty when the dependency applies to a host.
+type Dependency struct {
+	ChildHostName     string
+	ChildServiceName  string
+	ParentHostName    string
+	ParentServiceName string
+}
+
+type Endpoint struct {
+	Name                string
+	Connected           bool
+	LastMessageReceived time.Time
+}
+
+// Zone is a cluster zone. Global zones only distribute configuration and have no endpoints.
+type Zone struct {
 	Name      string
-	State     int
-	StateType int
+	Endpoints []string
+	Parent    string
+	Global    bool
+}
+
+// ActionTarget selects the objects of an action, either the hosts or the
+// services. Services are given as pairs, so only services that exist are hit.
+type ActionTarget struct {
+	Hosts    []string
+	Services []ServiceName
+}
+
+type ServiceName struct {
+	Host    string
+	Service string
+}
+
+// FullName returns the object name Icinga2 uses for the service, like "host!service".
+func (n ServiceName) FullName() string {
+	return n.Host + "!" + n.Service
+}
+
+type actionPayload struct {
+	Type       string                 `json:"type"`
+	Filter     string                 `json:"filter"`
+	FilterVars map[string]interface{} `json:"filter_vars"`
+	Author     string                 `json:"author"`
+	Comment    string                 `json:"comment"`
+	StartTime  int64                  `json:"start_time,omitempty"`
+	EndTime    int64                  `json:"end_time,omitempty"`
+	Fixed      bool                   `json:"fixed,omitempty"`
 }
 
 type HTTPError struct {
@@ -99,3 +309,17 @@ type HTTPError struct {
 func (e *HTTPError) Error() string {
 	return fmt.Sprintf("HTTP %d: %s - %s", e.StatusCode, e.Status, e.Body)
 }
+
+// secondsToDuration converts the fractional seconds used by Icinga2 for intervals.
+func secondsToDuration(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
+
+// unixToTime converts the fractional unix timestamps used by Icinga2.
+func unixToTime(value float64) time.Time {
+	if value == 0 {
+		return time.Time{}
+	}
+	seconds := int64(value)
+	return time.Unix(seconds, int64((value-float64(seconds))*float64(time.Second)))
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func icingaTestClient(programStart time.Time) stubDashboardClient {
+	return stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{
+			EnableNotifications: true, EnableHostChecks: true, EnableServiceChecks: true,
+			EnableEventHandlers: true, EnableFlapping: true, EnablePerfdata: true,
+			Version: "r2.14.2-1", NodeName: "master1", Environment: "production", PID: 4711,
+			ProgramStart: float64(programStart.Unix()),
+		},
+		cibStatus: &icinga2apiclient.CIBStatus{},
+	}
+}
+
+func TestNewIcingaInfo(t *testing.T) {
+	currentTime := time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
+	app := &icinga2apiclient.IcingaApplication{Version: "r2.14.2-1", NodeName: "master1", PID: 4711}
+
+	tests := []struct {
+		name              string
+		programStart      time.Time
+		restartWindow     time.Duration
+		uptime            string
+		recentlyRestarted bool
+	}{
+		{"running for days", currentTime.Add(-76 * time.Hour), 5 * time.Minute, "3d 4h", false},
+		{"just restarted", currentTime.Add(-2 * time.Minute), 5 * time.Minute, "2m", true},
+		{"banner disabled", currentTime.Add(-2 * time.Minute), 0, "2m", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app.ProgramStart = float64(tt.programStart.Unix())
+			info := newIcingaInfo(app, currentTime, time.UTC, "2006-01-02 15:04", tt.restartWindow)
+			if info.UptimeString() != tt.uptime || info.RecentlyRestarted != tt.recentlyRestarted {
+				t.Errorf("expected uptime %s and restarted %t, got %s and %t", tt.uptime, tt.recentlyRestarted, info.UptimeString(), info.RecentlyRestarted)
+			}
+			if info.ProgramStartString != tt.programStart.Format("2006-01-02 15:04") {
+				t.Errorf("unexpected program start %s", info.ProgramStartString)
+			}
+		})
+	}
+
+	app.ProgramStart = 0
+	if info := newIcingaInfo(app, currentTime, time.UTC, "15:04", time.Minute); info.Uptime != 0 || info.RecentlyRestarted || !info.ProgramStart.IsZero() {
+		t.Errorf("expected no uptime without program start, got %+v", info)
+	}
+}
+
+func TestRenderDashboardShowsRestartBannerAndFooter(t *testing.T) {
+	withAPITestClient(t, icingaTestClient(time.Date(2026, time.March, 11, 8, 5, 0, 0, time.UTC)))
+	originalWindow, originalFooter := restartWindow, defaultShowFooter
+	restartWindow = 5 * time.Minute
+	defaultShowFooter = false
+	defer func() { restartWindow, defaultShowFooter = originalWindow, originalFooter }()
+
+	rec := httptest.NewRecorder()
+	renderDashboard(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, "Icinga2 restarted 4m ago!") {
+		t.Errorf("expected restart banner, got %s", body)
+	}
+	if strings.Contains(body, `class="footer"`) {
+		t.Errorf("expected no footer by default, got %s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	renderDashboard(rec, httptest.NewRequest(http.MethodGet, "/?footer=1", nil))
+	body = rec.Body.String()
+	for _, expected := range []string{"Icinga2 r2.14.2-1", "Node master1", "Environment production", "PID 4711", "Up 4m"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in footer, got %s", expected, body)
+		}
+	}
+}
+
+func TestIcingaInfoInJSON(t *testing.T) {
+	withAPITestClient(t, icingaTestClient(time.Date(2026, time.March, 1, 12, 30, 0, 0, time.UTC)))
+
+	rec := httptest.NewRecorder()
+	renderJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard?fields=icinga", nil))
+	expected := `{"icinga":{"version":"r2.14.2-1","node_name":"master1","environment":"production","pid":4711,"program_start":1772368200,"uptime":848350,"recently_restarted":false,"program_start_rfc3339":"2026-03-01T12:30:00Z"}}`
+	if strings.TrimSpace(rec.Body.String()) != expected {
+		t.Errorf("expected %s, got %s", expected, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	renderJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard?fields=icinga_version,program_start", nil))
+	expected = `{"icinga_version":"r2.14.2-1","program_start":1772368200}`
+	if strings.TrimSpace(rec.Body.String()) != expected {
+		t.Errorf("expected %s, got %s", expected, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	serveAPIv2(rec, httptest.NewRequest(http.MethodGet, "/api/v2/summary?fields=icinga", nil))
+	if !strings.Contains(rec.Body.String(), `"program_start":"2026-03-01T12:30:00Z","uptime":848350`) {
+		t.Errorf("expected Icinga2 info in summary, got %s", rec.Body.String())
+	}
+}
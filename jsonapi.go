@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// volatileFields change with every request without the problems changing.
+// They are left out of ETags, so polling clients get 304 Not Modified as long
+// as the snapshot stays the same.
+// Properties of nested objects are given as paths like "icinga.uptime".
+var volatileFields = []string{
+	"time", "time_string", "timestamp", "date_string", "clocks", "icinga.uptime", "widgets",
+	// Check statistics change with every check
+	"cib_status.avg_latency", "cib_status.min_latency", "cib_status.max_latency",
+	"cib_status.avg_execution_time", "cib_status.min_execution_time", "cib_status.max_execution_time",
+	"cib_status.active_host_checks", "cib_status.active_service_checks",
+	"cib_status.passive_host_checks", "cib_status.passive_service_checks",
+	"cib_status.active_host_checks_1min", "cib_status.active_host_checks_5min", "cib_status.active_host_checks_15min",
+	"cib_status.active_service_checks_1min", "cib_status.active_service_checks_5min", "cib_status.active_service_checks_15min",
+	"cib_status.passive_host_checks_1min", "cib_status.passive_host_checks_5min", "cib_status.passive_host_checks_15min",
+	"cib_status.passive_service_checks_1min", "cib_status.passive_service_checks_5min", "cib_status.passive_service_checks_15min",
+	"cib_status.current_concurrent_checks", "cib_status.current_pending_callbacks", "cib_status.remote_check_queue",
+	"cib_status.uptime",
+}
+
+// listParams select a part of a list resource.
+type listParams struct {
+	// Maximum number of items, 0 means unlimited
+	Limit  int
+	Offset int
+	// JSON names of the properties to return, all if empty
+	Fields []string
+}
+
+func parseListParams(query url.Values) (listParams, error) {
+	var params listParams
+	for name, target := range map[string]*int{"limit": &params.Limit, "offset": &params.Offset} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return params, fmt.Errorf("%s must be a non-negative integer, got %q", name, value)
+		}
+		*target = parsed
+	}
+	params.Fields = splitList(query.Get("fields"))
+	return params, nil
+}
+
+// paginate returns the items selected by params and the offset of the next
+// page, which is nil on the last page.
+func paginate[T any](items []T, params listParams) ([]T, *int) {
+	start := min(params.Offset, len(items))
+	end := len(items)
+	if params.Limit > 0 {
+		end = min(start+params.Limit, len(items))
+	}
+	if end >= len(items) {
+		return items[start:end], nil
+	}
+	return items[start:end], &end
+}
+
+// projectFields reduces the JSON object of value to the given properties.
+func projectFields(value interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return value, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		property, ok := object[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown field: %s", field)
+		}
+		projected[field] = property
+	}
+	return projected, nil
+}
+
+// snapshotETag hashes a JSON body without its volatile fields.
+func snapshotETag(body []byte) string {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err == nil {
+		for _, field := range volatileFields {
+			removeField(object, field)
+		}
+		// Map keys are sorted, so equal snapshots get equal hashes
+		body, _ = json.Marshal(object)
+	}
+	// Weak, because the volatile fields still differ
+	return fmt.Sprintf(`W/"%x"`, sha1.Sum(body))
+}
+
+// removeField deletes the property at path from object.
+func removeField(object map[string]json.RawMessage, path string) {
+	name, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		delete(object, name)
+		return
+	}
+
+	var child map[string]json.RawMessage
+	if err := json.Unmarshal(object[name], &child); err != nil || child == nil {
+		return
+	}
+	removeField(child, rest)
+	object[name], _ = json.Marshal(child)
+}
+
+// matchesETag reports whether the If-None-Match header of r lists etag.
+func matchesETag(r *http.Request, etag string) bool {
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSONSnapshot writes value with an ETag, or only 304 Not Modified if
+// the client already has the same snapshot.
+func writeJSONSnapshot(w http.ResponseWriter, r *http.Request, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	etag := snapshotETag(body)
+	w.Header().Set("ETag", etag)
+	if matchesETag(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseListParams(t *testing.T) {
+	params, err := parseListParams(url.Values{"limit": {"10"}, "offset": {"20"}, "fields": {"name, state"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := listParams{Limit: 10, Offset: 20, Fields: []string{"name", "state"}}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("expected %+v, got %+v", want, params)
+	}
+
+	for _, query := range []url.Values{{"limit": {"ten"}}, {"offset": {"-1"}}} {
+		if _, err := parseListParams(query); err == nil {
+			t.Errorf("expected error for %v", query)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		params listParams
+		want   []int
+		next   int
+	}{
+		{listParams{}, []int{1, 2, 3, 4, 5}, -1},
+		{listParams{Limit: 2}, []int{1, 2}, 2},
+		{listParams{Limit: 2, Offset: 2}, []int{3, 4}, 4},
+		{listParams{Limit: 2, Offset: 4}, []int{5}, -1},
+		{listParams{Offset: 9}, []int{}, -1},
+	}
+
+	for _, test := range tests {
+		page, next := paginate(items, test.params)
+		if !reflect.DeepEqual(page, test.want) {
+			t.Errorf("%+v: expected %v, got %v", test.params, test.want, page)
+		}
+		if (next == nil) != (test.next == -1) || (next != nil && *next != test.next) {
+			t.Errorf("%+v: expected next offset %d, got %v", test.params, test.next, next)
+		}
+	}
+}
+
+func TestProjectFields(t *testing.T) {
+	projected, err := projectFields(apiHost{Name: "web-1", State: 1}, []string{"name", "state"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	data, _ := json.Marshal(projected)
+	if string(data) != `{"name":"web-1","state":1}` {
+		t.Errorf("unexpected projection %s", data)
+	}
+
+	if _, err := projectFields(apiHost{}, []string{"password"}); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestSnapshotETagIgnoresVolatileFields(t *testing.T) {
+	first := snapshotETag([]byte(`{"time_string":"08:09:10","timestamp":1,"services":[]}`))
+	second := snapshotETag([]byte(`{"timestamp":2,"time_string":"08:09:15","services":[]}`))
+	changed := snapshotETag([]byte(`{"time_string":"08:09:15","timestamp":2,"services":[{"name":"http"}]}`))
+
+	if first != second {
+		t.Errorf("expected equal ETags for equal snapshots, got %s and %s", first, second)
+	}
+	if first == changed {
+		t.Error("expected different ETags for different snapshots")
+	}
+
+	nested := snapshotETag([]byte(`{"icinga":{"version":"r2.14.2","uptime":60}}`))
+	if nested != snapshotETag([]byte(`{"icinga":{"uptime":65,"version":"r2.14.2"}}`)) {
+		t.Error("expected equal ETags when only the uptime changed")
+	}
+	if nested == snapshotETag([]byte(`{"icinga":{"version":"r2.14.3","uptime":65}}`)) {
+		t.Error("expected different ETags for different versions")
+	}
+}
+
+func TestRenderJSONReturnsNotModified(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+
+	rec := httptest.NewRecorder()
+	renderJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with ETag, got %d %q", rec.Code, etag)
+	}
+
+	// The clock moved on, but the problems are the same
+	now = func() time.Time {
+		return time.Date(2026, time.March, 11, 8, 9, 40, 0, time.UTC)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dashboard", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	renderJSON(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("expected 304 without body, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/dashboard?minState=2", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	renderJSON(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for a different snapshot, got %d", rec.Code)
+	}
+}
+
+func TestRenderJSONWithFields(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+
+	rec := httptest.NewRecorder()
+	renderJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard?fields=hosts,services", nil))
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(body) != 2 || body["hosts"] == nil || body["services"] == nil {
+		t.Errorf("expected only hosts and services, got %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	renderJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard?fields=unknown", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown field, got %d", rec.Code)
+	}
+}
+
+func TestRenderJSONIgnoresKioskLimits(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+	originalKiosk := defaultKiosk
+	t.Cleanup(func() { defaultKiosk = originalKiosk })
+	defaultKiosk = kioskSettings{Mode: kioskPaginate, PageSize: 1, PageSeconds: 10, MaxRows: 2}
+
+	rec := httptest.NewRecorder()
+	renderJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard", nil))
+
+	var body PageVariables
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(body.HostRecords) != 1 || len(body.ServiceRecords) != 2 || body.Overflow != "" || body.Pages > 1 {
+		t.Errorf("expected every problem, got %d hosts, %d services, overflow %q, %d pages", len(body.HostRecords), len(body.ServiceRecords), body.Overflow, body.Pages)
+	}
+}
+
+func TestAPIv2Pagination(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+
+	rec := httptest.NewRecorder()
+	serveAPIv2(rec, httptest.NewRequest(http.MethodGet, "/api/v2/services?groupBy=none&limit=2&offset=1&fields=hosts", nil))
+
+	var list struct {
+		Items      []map[string]json.RawMessage `json:"items"`
+		Total      int                          `json:"total"`
+		NextOffset *int                         `json:"next_offset"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if list.Total != 3 || len(list.Items) != 2 || list.NextOffset != nil {
+		t.Fatalf("expected the last 2 of 3 services, got %s", rec.Body.String())
+	}
+	for _, item := range list.Items {
+		if len(item) != 1 || item["hosts"] == nil {
+			t.Errorf("expected only hosts, got %v", item)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	serveAPIv2(rec, httptest.NewRequest(http.MethodGet, "/api/v2/hosts?fields=password", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown field, got %d", rec.Code)
+	}
+}
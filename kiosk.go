@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	kioskOff      = "off"
+	kioskFit      = "fit"
+	kioskPaginate = "paginate"
+)
+
+// kioskSettings control how the dashboard deals with more rows than fit on a screen.
+type kioskSettings struct {
+	// One of "off", "fit" or "paginate"
+	Mode string
+	// Rows per page in paginate mode
+	PageSize int
+	// Seconds each page is shown in paginate mode
+	PageSeconds int
+	// Maximum number of rows, the rest is summarized in an overflow row. 0 means unlimited.
+	MaxRows int
+}
+
+func isValidKioskMode(mode string) bool {
+	return mode == kioskOff || mode == kioskFit || mode == kioskPaginate
+}
+
+// applyRowLimit drops all rows beyond maxRows and summarizes them
+// in pageVariables.Overflow. Hosts are shown before services, so services
+// are dropped first.
+func applyRowLimit(pageVariables *PageVariables, maxRows int) {
+	if maxRows <= 0 || len(pageVariables.HostRecords)+len(pageVariables.ServiceRecords) <= maxRows {
+		return
+	}
+	// One row is needed for the overflow summary itself
+	visible := maxRows - 1
+
+	var droppedHosts []PageHostListRecord
+	if len(pageVariables.HostRecords) > visible {
+		droppedHosts = pageVariables.HostRecords[visible:]
+		pageVariables.HostRecords = pageVariables.HostRecords[:visible]
+	}
+	visible -= len(pageVariables.HostRecords)
+
+	var droppedServices []PageServiceListRecord
+	if len(pageVariables.ServiceRecords) > visible {
+		droppedServices = pageVariables.ServiceRecords[visible:]
+		pageVariables.ServiceRecords = pageVariables.ServiceRecords[:visible]
+	}
+
+	pageVariables.Overflow = overflowSummary(droppedHosts, droppedServices, pageVariables.Messages)
+}
+
+// overflowSummary describes the given records, e.g. "+2 more hosts down, +12 more warnings".
+func overflowSummary(hosts []PageHostListRecord, services []PageServiceListRecord, messages localizer) string {
+	var parts []string
+	if len(hosts) > 0 {
+		parts = append(parts, messages.T("more_hosts_down", len(hosts)))
+	}
+
+	labels := []string{"more_ok", "more_warning", "more_critical", "more_unknown"}
+	counts := make([]int, len(labels))
+	for _, service := range services {
+		if service.State >= 0 && service.State < len(labels) {
+			counts[service.State]++
+		}
+	}
+	// Worst state first
+	for _, state := range []int{2, 1, 3, 0} {
+		if counts[state] > 0 {
+			parts = append(parts, messages.T(labels[state], counts[state]))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// applyPagination reduces the rows to the page that is due at currentTime.
+// Pages rotate every settings.PageSeconds, so every screen showing the same
+// dashboard shows the same page.
+func applyPagination(pageVariables *PageVariables, settings kioskSettings, currentTime time.Time) {
+	total := len(pageVariables.HostRecords) + len(pageVariables.ServiceRecords)
+	if settings.PageSize <= 0 || total <= settings.PageSize {
+		return
+	}
+
+	pages := (total + settings.PageSize - 1) / settings.PageSize
+	pageSeconds := int64(settings.PageSeconds)
+	if pageSeconds <= 0 {
+		pageSeconds = refreshIntervalSeconds
+	}
+	page := int((currentTime.Unix() / pageSeconds) % int64(pages))
+
+	start := page * settings.PageSize
+	end := start + settings.PageSize
+
+	hostCount := len(pageVariables.HostRecords)
+	pageVariables.HostRecords = pageVariables.HostRecords[min(start, hostCount):min(end, hostCount)]
+	pageVariables.ServiceRecords = pageVariables.ServiceRecords[min(max(start-hostCount, 0), len(pageVariables.ServiceRecords)):min(max(end-hostCount, 0), len(pageVariables.ServiceRecords))]
+
+	pageVariables.Page = page + 1
+	pageVariables.Pages = pages
+	// Reload in time to show the next page
+	if int(pageSeconds) < pageVariables.RefreshInterval {
+		pageVariables.RefreshInterval = int(pageSeconds)
+	}
+}
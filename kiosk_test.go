@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func buildKioskPage(hosts int, services []int) PageVariables {
+	page := PageVariables{RefreshInterval: refreshIntervalSeconds}
+	for i := 0; i < hosts; i++ {
+		page.HostRecords = append(page.HostRecords, PageHostListRecord{Name: fmt.Sprintf("host-%d", i), State: 1})
+	}
+	for i, state := range services {
+		page.ServiceRecords = append(page.ServiceRecords, PageServiceListRecord{Name: fmt.Sprintf("service-%d", i), State: state})
+	}
+	return page
+}
+
+func TestApplyRowLimit(t *testing.T) {
+	page := buildKioskPage(2, []int{2, 1, 1, 1, 3})
+
+	applyRowLimit(&page, 4)
+
+	if len(page.HostRecords) != 2 || len(page.ServiceRecords) != 1 {
+		t.Fatalf("expected 2 hosts and 1 service, got %d and %d", len(page.HostRecords), len(page.ServiceRecords))
+	}
+	if page.Overflow != "+3 more warnings, +1 more unknowns" {
+		t.Errorf("unexpected overflow summary %q", page.Overflow)
+	}
+}
+
+func TestApplyRowLimit_DropsHosts(t *testing.T) {
+	page := buildKioskPage(3, []int{2})
+
+	applyRowLimit(&page, 2)
+
+	if len(page.HostRecords) != 1 || len(page.ServiceRecords) != 0 {
+		t.Fatalf("expected 1 host and no services, got %d and %d", len(page.HostRecords), len(page.ServiceRecords))
+	}
+	if page.Overflow != "+2 more hosts down, +1 more criticals" {
+		t.Errorf("unexpected overflow summary %q", page.Overflow)
+	}
+}
+
+func TestApplyRowLimit_Unlimited(t *testing.T) {
+	page := buildKioskPage(3, []int{2, 1})
+
+	applyRowLimit(&page, 0)
+	applyRowLimit(&page, 5)
+
+	if len(page.HostRecords) != 3 || len(page.ServiceRecords) != 2 || page.Overflow != "" {
+		t.Errorf("expected all rows to be kept, got %+v", page)
+	}
+}
+
+func TestApplyPagination(t *testing.T) {
+	settings := kioskSettings{Mode: kioskPaginate, PageSize: 3, PageSeconds: 10}
+
+	tests := []struct {
+		seconds  int64
+		page     int
+		hosts    []string
+		services []string
+	}{
+		{0, 1, []string{"host-0", "host-1"}, []string{"service-0"}},
+		{10, 2, nil, []string{"service-1", "service-2", "service-3"}},
+		{25, 3, nil, []string{"service-4"}},
+		// Back to the first page
+		{30, 1, []string{"host-0", "host-1"}, []string{"service-0"}},
+	}
+
+	for _, tt := range tests {
+		page := buildKioskPage(2, []int{2, 2, 1, 1, 1})
+		applyPagination(&page, settings, time.Unix(tt.seconds, 0))
+
+		if page.Page != tt.page || page.Pages != 3 {
+			t.Errorf("at %ds expected page %d/3, got %d/%d", tt.seconds, tt.page, page.Page, page.Pages)
+		}
+		assertOrder(t, hostNames(page.HostRecords), tt.hosts)
+		assertOrder(t, serviceNames(page.ServiceRecords), tt.services)
+	}
+}
+
+func TestApplyPagination_SinglePage(t *testing.T) {
+	page := buildKioskPage(1, []int{2})
+
+	applyPagination(&page, kioskSettings{Mode: kioskPaginate, PageSize: 15, PageSeconds: 10}, time.Unix(0, 0))
+
+	if page.Pages != 0 || len(page.HostRecords) != 1 || len(page.ServiceRecords) != 1 {
+		t.Errorf("expected no pagination, got %+v", page)
+	}
+}
+
+func TestApplyPagination_RefreshesForNextPage(t *testing.T) {
+	page := buildKioskPage(4, nil)
+
+	applyPagination(&page, kioskSettings{Mode: kioskPaginate, PageSize: 2, PageSeconds: 3}, time.Unix(0, 0))
+
+	if page.RefreshInterval != 3 {
+		t.Errorf("expected refresh interval to follow the page duration, got %d", page.RefreshInterval)
+	}
+}
@@ -3,33 +3,75 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
-	"text/template"
+	"strings"
 	"time"
 
 	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
 )
 
+// How often the dashboard reloads itself in a browser
+const refreshIntervalSeconds = 5
+
 var (
-	client              dashboardClient
-	defaultMinState     int
-	defaultMaxState     int
-	defaultMinStateType int
-	baseURL             string
-	now                 = time.Now
+	client               dashboardClient
+	defaultMinState      int
+	defaultMaxState      int
+	defaultMinStateType  int
+	defaultGrouping      serviceGrouping
+	defaultExpandMode    string
+	defaultSortOrder     string
+	defaultKiosk         kioskSettings
+	defaultClocks        clockSettings
+	clusterLogLagSeconds int
+	staleFactor          int
+	restartWindow        time.Duration
+	defaultShowFooter    bool
+	defaultWidgets       []string
+	priorityVar          string
+	weighting            severityWeighting
+	themes               map[string]*theme
+	defaultTheme         string
+	views                viewConfig
+	webhooks             webhookConfig
+	alerts               alertSettings
+	problems             = newProblemTracker()
+	problemFeed          = newChangeFeed(100)
+	baseURL              string
+	now                  = time.Now
+	templates            = newTemplateLoader(embeddedFiles, false)
 )
 
+// logOutput receives the error log lines, the terminal UI discards them.
+var logOutput io.Writer = os.Stdout
+
 type dashboardClient interface {
 	GetIcingaApplicationStatus() (*icinga2apiclient.IcingaApplication, error)
 	GetCIBStatus() (*icinga2apiclient.CIBStatus, error)
 	GetServices(minState int, maxState int, minStateType int) ([]icinga2apiclient.Service, error)
 	GetHosts(minStateType int) ([]icinga2apiclient.Host, error)
+	GetDependencies() ([]icinga2apiclient.Dependency, error)
+	GetAPIListenerStatus() (*icinga2apiclient.APIListenerStatus, error)
+	GetEndpoints() ([]icinga2apiclient.Endpoint, error)
+	GetZones() ([]icinga2apiclient.Zone, error)
+	GetStaleHosts(factor int) ([]icinga2apiclient.Host, error)
+	GetStaleServices(factor int) ([]icinga2apiclient.Service, error)
+	AcknowledgeProblem(target icinga2apiclient.ActionTarget, author string, comment string) error
+	ScheduleDowntime(target icinga2apiclient.ActionTarget, author string, comment string, start time.Time, end time.Time) error
 }
 
 func main() {
+	// "icinga-dashboard cli" prints the problems of a running dashboard in the terminal
+	if len(os.Args) > 1 && os.Args[1] == "cli" {
+		os.Exit(runCLI(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	envVariables := parseEnvVariables()
 
 	apiClient, err := icinga2apiclient.NewClient(
@@ -41,7 +83,7 @@ func main() {
 		envVariables["ICINGA2_API_VALIDATE_CERTIFICATE"].(int) == 1,
 	)
 	if err != nil {
-		fmt.Printf("Error configuring API client: %v\n", err)
+		fmt.Fprintf(logOutput, "Error configuring API client: %v\n", err)
 	}
 
 	apiClient.Username = envVariables["ICINGA2_API_USERNAME"].(string)
@@ -51,15 +93,142 @@ func main() {
 	defaultMinState = envVariables["MIN_STATE"].(int)
 	defaultMaxState = envVariables["MAX_STATE"].(int)
 	defaultMinStateType = envVariables["MIN_STATE_TYPE"].(int)
+	defaultGrouping = serviceGrouping{
+		By:        envVariables["GROUP_BY"].(string),
+		Threshold: envVariables["GROUP_THRESHOLD"].(int),
+	}
+	if !isValidGroupBy(defaultGrouping.By) {
+		panic("GROUP_BY has an invalid value: " + defaultGrouping.By)
+	}
+	defaultExpandMode = envVariables["EXPAND_AGGREGATED"].(string)
+	if !isValidExpandMode(defaultExpandMode) {
+		panic("EXPAND_AGGREGATED has an invalid value: " + defaultExpandMode)
+	}
+	defaultSortOrder = envVariables["SORT"].(string)
+	if !isValidSortOrder(defaultSortOrder) {
+		panic("SORT has an invalid value: " + defaultSortOrder)
+	}
+	priorityVar = envVariables["PRIORITY_VAR"].(string)
+	defaultKiosk = kioskSettings{
+		Mode:        envVariables["KIOSK_MODE"].(string),
+		PageSize:    envVariables["KIOSK_PAGE_SIZE"].(int),
+		PageSeconds: envVariables["KIOSK_PAGE_SECONDS"].(int),
+		MaxRows:     envVariables["MAX_ROWS"].(int),
+	}
+	if !isValidKioskMode(defaultKiosk.Mode) {
+		panic("KIOSK_MODE has an invalid value: " + defaultKiosk.Mode)
+	}
+	defaultClocks.Location, err = parseTimezone(envVariables["TIMEZONE"].(string))
+	if err != nil {
+		panic("TIMEZONE has an invalid value: " + err.Error())
+	}
+	defaultClocks.Clocks, err = parseClocks(envVariables["CLOCKS"].(string))
+	if err != nil {
+		panic("CLOCKS has an invalid value: " + err.Error())
+	}
+	defaultClocks.ShowDate = envVariables["SHOW_DATE"].(int) == 1
+	alerts = alertSettings{
+		NewDuration: time.Duration(envVariables["ALERT_NEW_SECONDS"].(int)) * time.Second,
+		SoundURL:    envVariables["ALERT_SOUND_URL"].(string),
+		Sound:       envVariables["ALERT_SOUND"].(int) == 1,
+		Flash:       envVariables["ALERT_FLASH"].(int) == 1,
+	}
+	alerts.Quiet, err = parseQuietHours(envVariables["ALERT_QUIET_HOURS"].(string))
+	if err != nil {
+		panic("ALERT_QUIET_HOURS has an invalid value: " + err.Error())
+	}
+	views, err = loadViewConfig(envVariables["VIEWS_FILE"].(string))
+	if err != nil {
+		panic("VIEWS_FILE can't be loaded: " + err.Error())
+	}
+	webhooks, err = loadWebhookConfig(envVariables["WEBHOOKS_FILE"].(string))
+	if err != nil {
+		panic("WEBHOOKS_FILE can't be loaded: " + err.Error())
+	}
+	weighting, err = parseSeverityWeighting(envVariables["SEVERITY_WEIGHTS"].(string), envVariables["SEVERITY_THRESHOLDS"].(string))
+	if err != nil {
+		panic("SEVERITY_WEIGHTS or SEVERITY_THRESHOLDS has an invalid value: " + err.Error())
+	}
 	baseURL = envVariables["ICINGA2_BASE_URL"].(string)
+	clusterLogLagSeconds = envVariables["CLUSTER_LOG_LAG_SECONDS"].(int)
+	staleFactor = envVariables["STALE_FACTOR"].(int)
+	restartWindow = time.Duration(envVariables["RESTART_BANNER_SECONDS"].(int)) * time.Second
+	defaultShowFooter = envVariables["SHOW_FOOTER"].(int) == 1
+	defaultWidgets, err = parseWidgets(envVariables["INFO_BAR_WIDGETS"].(string))
+	if err != nil {
+		panic("INFO_BAR_WIDGETS has an invalid value: " + err.Error())
+	}
+
+	// Embedded files can be replaced one by one with files from the override directory.
+	// In dev mode files are read from the working directory instead of the binary.
+	var files fs.FS = embeddedFiles
+	if envVariables["DEV_MODE"].(int) == 1 {
+		files = os.DirFS(".")
+	}
+	if overrideDir := envVariables["OVERRIDE_DIR"].(string); overrideDir != "" {
+		files = layeredFS{os.DirFS(overrideDir), files}
+	}
+	templates = newTemplateLoader(files, envVariables["DEV_MODE"].(int) == 1)
+	if _, err := templates.Get(); err != nil {
+		panic(err)
+	}
+	assets, err := fs.Sub(files, "assets")
+	if err != nil {
+		panic(err)
+	}
+	themeFiles, err := fs.Sub(files, "themes")
+	if err != nil {
+		panic(err)
+	}
+	themes, err = loadThemes(files)
+	if err != nil {
+		panic(err)
+	}
+	catalogs, err = loadCatalogs(files)
+	if err != nil {
+		panic(err)
+	}
+	defaultLanguage = strings.ToLower(envVariables["DEFAULT_LANGUAGE"].(string))
+	if _, ok := catalogs[defaultLanguage]; !ok {
+		panic("DEFAULT_LANGUAGE has an invalid value: " + defaultLanguage)
+	}
+	defaultTheme = envVariables["THEME"].(string)
+	if _, ok := themes[defaultTheme]; !ok {
+		panic("THEME has an invalid value: " + defaultTheme)
+	}
+
+	// "icinga-dashboard tui" shows the dashboard in the terminal instead of serving it
+	if len(os.Args) > 1 && os.Args[1] == "tui" {
+		os.Exit(runTUI(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
 
 	// If path starts with /assets/ then serve static files from assets/
-	http.Handle("/assets/", http.StripPrefix("/assets/", http.FileServer(http.Dir("assets"))))
-	http.Handle("/favicon.ico", http.FileServer(http.Dir("assets")))
+	http.Handle("/assets/", http.StripPrefix("/assets/", http.FileServer(http.FS(assets))))
+	http.Handle("/favicon.ico", http.FileServer(http.FS(files)))
+	http.Handle("/themes/", http.StripPrefix("/themes/", http.FileServer(http.FS(themeFiles))))
 
 	// Define the handler for the root URL
 	http.HandleFunc("/", renderDashboard)
+	http.HandleFunc("/kiosk", renderKiosk)
 	http.HandleFunc("/api/v1/dashboard", renderJSON)
+	http.HandleFunc("/api/v1/dashboard.csv", renderCSV)
+	http.HandleFunc("/api/v2/", serveAPIv2)
+	http.HandleFunc("/feed.atom", renderFeed)
+
+	if seconds := envVariables["SNAPSHOT_SECONDS"].(int); seconds > 0 {
+		feedSize := envVariables["FEED_SIZE"].(int)
+		if feedSize < 1 {
+			panic("FEED_SIZE has an invalid value: " + strconv.Itoa(feedSize))
+		}
+		problemFeed = newChangeFeed(feedSize)
+		dispatcher := newWebhookDispatcher(webhooks)
+		go watchSnapshots(time.Duration(seconds)*time.Second,
+			func(pageVariables PageVariables) {
+				problemFeed.update(exportRows(pageVariables), pageVariables.Time.Time)
+			},
+			dispatcher.observe,
+		)
+	}
 
 	fmt.Printf("Starting webserver. Listening on %s\n", envVariables["LISTEN_ADDRESS"])
 	err = http.ListenAndServe(envVariables["LISTEN_ADDRESS"].(string), nil)
@@ -69,18 +238,21 @@ func main() {
 }
 
 func renderDashboard(w http.ResponseWriter, r *http.Request) {
-	pageVariables := buildPageVariables(r)
+	renderPage(w, buildPageVariables(r, problems))
+}
+
+func renderPage(w http.ResponseWriter, pageVariables PageVariables) {
 	if pageVariables.Error != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 
-	tmpl, tmplErr := template.ParseFiles("index.html")
+	tmpl, tmplErr := templates.Get()
 	if tmplErr != nil {
 		http.Error(w, tmplErr.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	errExec := tmpl.Execute(w, pageVariables)
+	errExec := tmpl.ExecuteTemplate(w, "index.html", pageVariables)
 	if errExec != nil {
 		http.Error(w, errExec.Error(), http.StatusInternalServerError)
 		return
@@ -88,25 +260,40 @@ func renderDashboard(w http.ResponseWriter, r *http.Request) {
 }
 
 func renderJSON(w http.ResponseWriter, r *http.Request) {
-	pageVariables := buildPageVariables(r)
+	switch format := r.URL.Query().Get("format"); format {
+	case "csv", "text":
+		renderExport(w, r, format)
+		return
+	}
+
+	// Row limits and pages are for screens, API clients get every problem
+	pageVariables := buildPageVariables(completeRequest(r), nil)
 	if pageVariables.Error != nil {
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
+		err := json.NewEncoder(w).Encode(pageVariables)
+		if err != nil {
+			fmt.Fprintf(logOutput, "Error writing JSON response: %v\n", err)
+		}
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	err := json.NewEncoder(w).Encode(pageVariables)
+	projected, err := projectFields(pageVariables, splitList(r.URL.Query().Get("fields")))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	writeJSONSnapshot(w, r, projected)
 }
 
-func buildPageVariables(r *http.Request) PageVariables {
+// buildPageVariables takes a snapshot of the problems shown by r. Problems are
+// marked new only with a tracker, which the screens pass.
+func buildPageVariables(r *http.Request, tracker *problemTracker) PageVariables {
 	minStateType := defaultMinStateType
 	minState := defaultMinState
 	maxState := defaultMaxState
 
-	queryParamters := r.URL.Query()
+	queryParamters := withView(r.URL.Query())
 	if value, err := strconv.Atoi(queryParamters.Get("minStateType")); err == nil {
 		minStateType = value
 	}
@@ -116,101 +303,278 @@ func buildPageVariables(r *http.Request) PageVariables {
 	if value, err := strconv.Atoi(queryParamters.Get("maxState")); err == nil {
 		maxState = value
 	}
+	grouping := defaultGrouping
+	if value := queryParamters.Get("groupBy"); isValidGroupBy(value) {
+		grouping.By = value
+	}
+	if value, err := strconv.Atoi(queryParamters.Get("groupThreshold")); err == nil {
+		grouping.Threshold = value
+	}
+	expandMode := defaultExpandMode
+	if value := queryParamters.Get("expand"); isValidExpandMode(value) {
+		expandMode = value
+	}
+	sortOrder := defaultSortOrder
+	if value := queryParamters.Get("sort"); isValidSortOrder(value) {
+		sortOrder = value
+	}
+	kiosk := defaultKiosk
+	if value := queryParamters.Get("kiosk"); isValidKioskMode(value) {
+		kiosk.Mode = value
+	}
+	if value, err := strconv.Atoi(queryParamters.Get("pageSize")); err == nil {
+		kiosk.PageSize = value
+	}
+	if value, err := strconv.Atoi(queryParamters.Get("pageSeconds")); err == nil {
+		kiosk.PageSeconds = value
+	}
+	if value, err := strconv.Atoi(queryParamters.Get("maxRows")); err == nil {
+		kiosk.MaxRows = value
+	}
+
+	clocks := defaultClocks
+	if location, err := parseTimezone(queryParamters.Get("tz")); err == nil && location != nil {
+		clocks.Location = location
+	}
+	if value := queryParamters.Get("clocks"); value != "" {
+		if parsed, err := parseClocks(value); err == nil {
+			clocks.Clocks = parsed
+		}
+	}
+	if value, err := strconv.Atoi(queryParamters.Get("date")); err == nil {
+		clocks.ShowDate = value == 1
+	}
+	widgets := defaultWidgets
+	if value := queryParamters.Get("widgets"); value != "" {
+		if parsed, err := parseWidgets(value); err == nil {
+			widgets = parsed
+		}
+	}
+	showFooter := defaultShowFooter
+	if value, err := strconv.Atoi(queryParamters.Get("footer")); err == nil {
+		showFooter = value == 1
+	}
+
+	language := negotiateLanguage(r, queryParamters)
+	messages := newLocalizer(language)
 
 	currentTime := now()
+	localTime := currentTime
+	if clocks.Location != nil {
+		localTime = currentTime.In(clocks.Location)
+	}
 	pageVariables := PageVariables{
-		TimeString:   currentTime.Format("15:04:05"),
-		Time:         timestamp{currentTime},
-		MinStateType: stateTypeNumToString(minStateType),
-		MinState:     stateNumToString(minState),
-		MaxState:     stateNumToString(maxState),
-		BaseURL:      baseURL,
+		TimeString:      localTime.Format(messages.T("time_format")),
+		TimeRFC3339:     localTime.Format(time.RFC3339),
+		Timezone:        localTime.Location().String(),
+		Clocks:          renderClocks(clocks.Clocks, currentTime, messages.T("time_format")),
+		Time:            timestamp{currentTime},
+		MinStateType:    stateTypeNumToString(minStateType),
+		MinState:        stateNumToString(minState),
+		MaxState:        stateNumToString(maxState),
+		GroupBy:         grouping.By,
+		SortOrder:       sortOrder,
+		BaseURL:         baseURL,
+		RefreshInterval: refreshIntervalSeconds,
+		Theme:           selectTheme(queryParamters.Get("theme")),
+		KioskMode:       kiosk.Mode,
+		Language:        language,
+		Messages:        messages,
+		ShowFooter:      showFooter,
+	}
+	if clocks.ShowDate {
+		pageVariables.DateString = localTime.Format(messages.T("date_format"))
+	}
+	if _, ok := views.Views[queryParamters.Get("view")]; ok {
+		pageVariables.View = queryParamters.Get("view")
 	}
 
 	if appStatus, err := client.GetIcingaApplicationStatus(); err != nil {
-		fmt.Printf("Error getting IcingaApplication status: %v\n", err)
+		fmt.Fprintf(logOutput, "Error getting IcingaApplication status: %v\n", err)
 		pageVariables.Error = err
 	} else {
 		pageVariables.NotificationsDisabled = !appStatus.EnableNotifications
+		pageVariables.DisabledFeatures = disabledFeatures(appStatus)
+		pageVariables.Icinga = newIcingaInfo(appStatus, currentTime, localTime.Location(), messages.T("date_format")+" "+messages.T("time_format"), restartWindow)
+		pageVariables.IcingaVersion = pageVariables.Icinga.Version
+		pageVariables.ProgramStart = pageVariables.Icinga.ProgramStart
 	}
 
 	if cibStatus, err := client.GetCIBStatus(); err != nil {
-		fmt.Printf("Error getting hosts: %v\n", err)
+		fmt.Fprintf(logOutput, "Error getting hosts: %v\n", err)
 		pageVariables.Error = err
 	} else {
 		pageVariables.CIBStatus = cibStatus
+		pageVariables.Widgets = renderWidgets(widgets, cibStatus, messages)
 	}
 
-	pageVariables.ServiceRecords = getAndSortServices(client, minState, maxState, minStateType)
+	pageVariables.ClusterRecords = getClusterRecords(client, clusterLogLagSeconds)
+
+	hostGroup := queryParamters.Get("hostGroup")
+	pageVariables.StaleRecords = getStaleRecords(client, staleFactor, hostGroup, pageVariables.DisabledFeatures, currentTime)
+	pageVariables.StaleCount = len(pageVariables.StaleRecords)
+	serviceRecords, err := getAndSortServices(client, minState, maxState, minStateType, hostGroup, grouping)
+	if err != nil {
+		pageVariables.Error = err
+	}
+	pageVariables.ServiceRecords = serviceRecords
 
 	hosts, err := client.GetHosts(minStateType)
 	if err != nil {
-		fmt.Printf("Error getting hosts: %v\n", err)
+		fmt.Fprintf(logOutput, "Error getting hosts: %v\n", err)
 		pageVariables.Error = err
 	}
 
-	pageVariables.HostRecords = make([]PageHostListRecord, 0)
-	for _, host := range hosts {
-		pageVariables.HostRecords = append(pageVariables.HostRecords, PageHostListRecord{
-			Name:      host.Name,
-			State:     host.State,
-			StateType: host.StateType,
-		})
+	// Without dependencies we can still render every host on its own row
+	dependencies, err := client.GetDependencies()
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error getting dependencies: %v\n", err)
 	}
 
-	sort.Sort(ByState(pageVariables.ServiceRecords))
-	sort.Sort(ByName(pageVariables.HostRecords))
+	if hostGroup != "" {
+		hosts = slices.DeleteFunc(hosts, func(host icinga2apiclient.Host) bool {
+			return !slices.Contains(host.Groups, hostGroup)
+		})
+	}
+	pageVariables.HostRecords = buildHostListRecords(hosts, dependencies)
+
+	sortServiceRecords(pageVariables.ServiceRecords, sortOrder)
+	sortHostRecords(pageVariables.HostRecords, sortOrder)
+	applyExpandMode(pageVariables.ServiceRecords, expandMode, currentTime)
+
+	filters := fmt.Sprintf("minState=%d&maxState=%d&minStateType=%d&hostGroup=%s", minState, maxState, minStateType, hostGroup)
+	markNewProblems(&pageVariables, tracker, filters, alerts, currentTime)
+	// Quiet hours are meant in the timezone of the dashboard, not of the server
+	muted := queryParamters.Get("mute") == "1" || alerts.Quiet.contains(localTime)
+	pageVariables.AlertSound = alerts.Sound && !muted
+	pageVariables.AlertSoundURL = alerts.SoundURL
+	pageVariables.AlertFlash = alerts.Flash
+
+	applyRowLimit(&pageVariables, kiosk.MaxRows)
+	if kiosk.Mode == kioskPaginate {
+		applyPagination(&pageVariables, kiosk, currentTime)
+	}
 
 	return pageVariables
 }
 
-func getAndSortServices(client dashboardClient, minState int, maxState int, minStateType int) []PageServiceListRecord {
+func getAndSortServices(client dashboardClient, minState int, maxState int, minStateType int, hostGroup string, grouping serviceGrouping) ([]PageServiceListRecord, error) {
 	services, err := client.GetServices(minState, maxState, minStateType)
 	if err != nil {
-		fmt.Printf("Error getting hosts: %v\n", err)
+		fmt.Fprintf(logOutput, "Error getting services: %v\n", err)
+		return nil, err
+	}
+
+	if hostGroup != "" {
+		services = slices.DeleteFunc(services, func(service icinga2apiclient.Service) bool {
+			return !slices.Contains(service.HostGroups, hostGroup)
+		})
 	}
 
-	return buildServiceListRecords(services)
+	return buildServiceListRecords(services, grouping), nil
 }
 
-func buildServiceListRecords(services []icinga2apiclient.Service) []PageServiceListRecord {
-	groupedServices := make(map[string][]icinga2apiclient.Service)
-	for _, service := range services {
-		groupKey := fmt.Sprintf("%s-%d-%d", service.ServiceName, service.State, service.StateType)
-		groupedServices[groupKey] = append(groupedServices[groupKey], service)
+// buildHostListRecords turns hosts into page records. Unreachable hosts whose
+// failed parent is part of the list are collapsed into the parent's record.
+func buildHostListRecords(hosts []icinga2apiclient.Host, dependencies []icinga2apiclient.Dependency) []PageHostListRecord {
+	hostsByName := make(map[string]icinga2apiclient.Host)
+	for _, host := range hosts {
+		hostsByName[host.Name] = host
 	}
 
-	var resultSet []PageServiceListRecord
+	parents := make(map[string][]string)
+	for _, dependency := range dependencies {
+		// Only host to host dependencies decide about host reachability
+		if dependency.ChildServiceName != "" || dependency.ParentServiceName != "" {
+			continue
+		}
+		parents[dependency.ChildHostName] = append(parents[dependency.ChildHostName], dependency.ParentHostName)
+	}
 
-	for _, group := range groupedServices {
-		var hostField string
-		var isAggregated bool
-		if len(group) == 1 {
-			hostField = group[0].HostName
-			isAggregated = false
-		} else {
-			hostField = fmt.Sprintf("%d Hosts", len(group))
-			isAggregated = true
+	unreachableChildren := make(map[string][]icinga2apiclient.Host)
+	var rootHosts []icinga2apiclient.Host
+	for _, host := range hosts {
+		if !host.Unreachable {
+			rootHosts = append(rootHosts, host)
+			continue
 		}
 
-		resultSet = append(resultSet, PageServiceListRecord{
-			HostField:    hostField,
-			Name:         group[0].ServiceName,
-			State:        group[0].State,
-			StateType:    group[0].StateType,
-			IsAggregated: isAggregated,
-			AggregatedHosts: func() []string {
-				var hosts []string
-				for _, service := range group {
-					hosts = append(hosts, service.HostName)
-				}
-				return hosts
-			}(),
-			AggregatedHostsCount: len(group),
+		rootCause := findRootCause(host.Name, hostsByName, parents)
+		if rootCause == "" {
+			rootHosts = append(rootHosts, host)
+			continue
+		}
+		unreachableChildren[rootCause] = append(unreachableChildren[rootCause], host)
+	}
+
+	records := make([]PageHostListRecord, 0)
+	for _, host := range rootHosts {
+		children := unreachableChildren[host.Name]
+		sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+		var names []string
+		var members []PageUnreachableHost
+		for _, child := range children {
+			names = append(names, child.Name)
+			members = append(members, PageUnreachableHost{Name: child.Name, StateType: child.StateType, LastStateChange: timestamp{child.LastStateChange}})
+		}
+		records = append(records, PageHostListRecord{
+			Name:                  host.Name,
+			State:                 host.State,
+			StateType:             host.StateType,
+			UnreachableHosts:      names,
+			UnreachableHostsCount: len(names),
+			UnreachableMembers:    members,
+			LastStateChange:       timestamp{host.LastStateChange},
+			Priority:              lookupPriority(host.Vars),
 		})
 	}
 
-	return resultSet
+	return records
+}
+
+// findRootCause walks up the dependency chain of an unreachable host and
+// returns the first failed parent that is reachable itself. If all failed
+// parents are unreachable too, the topmost of them is returned. It returns an
+// empty string if no parent of the host is part of the given hosts.
+func findRootCause(hostName string, hostsByName map[string]icinga2apiclient.Host, parents map[string][]string) string {
+	visited := map[string]bool{hostName: true}
+	queue := []string{hostName}
+	var topmost string
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, parentName := range parents[current] {
+			if visited[parentName] {
+				continue
+			}
+			visited[parentName] = true
+
+			parent, ok := hostsByName[parentName]
+			if !ok {
+				continue
+			}
+			if !parent.Unreachable {
+				return parentName
+			}
+			if topmost == "" && !hasFailedParent(parentName, hostsByName, parents) {
+				topmost = parentName
+			}
+			queue = append(queue, parentName)
+		}
+	}
+
+	return topmost
+}
+
+func hasFailedParent(hostName string, hostsByName map[string]icinga2apiclient.Host, parents map[string][]string) bool {
+	for _, parentName := range parents[hostName] {
+		if _, ok := hostsByName[parentName]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 func parseEnvVariables() map[string]interface{} {
@@ -285,6 +649,188 @@ func parseEnvVariables() map[string]interface{} {
 		// 1 => hard state
 		// This value can be overwritten by the query parameter "maxStateType" when opening the dashboard in a browser.
 		"MIN_STATE_TYPE": 0,
+
+		// How services are grouped into a single row on the dashboard.
+		// Possible values
+		// service     => Same service on multiple hosts
+		// host        => Multiple services on the same host
+		// hostgroup   => Services of hosts sharing a host group. A host in several host groups is shown only once,
+		//                in the alphabetically first of its groups.
+		// vars.<name> => Services sharing the same value of a custom var, e.g. vars.team
+		// none        => Don't group at all
+		// This value can be overwritten by the query parameter "groupBy" when opening the dashboard in a browser.
+		"GROUP_BY": "service",
+
+		// Minimum number of services a group needs to have before it is collapsed into a single row.
+		// This value can be overwritten by the query parameter "groupThreshold" when opening the dashboard in a browser.
+		"GROUP_THRESHOLD": 2,
+
+		// Whether aggregated rows show the list of affected hosts right away.
+		// Possible values
+		// none  => Only expand rows on click
+		// all   => Expand all aggregated rows
+		// cycle => Expand one aggregated row after another on every refresh, useful for kiosk screens
+		// This value can be overwritten by the query parameter "expand" when opening the dashboard in a browser.
+		"EXPAND_AGGREGATED": "none",
+
+		// Order of the hosts and services on the dashboard.
+		// Possible values
+		// severity => Worst state first
+		// duration => Longest lasting problem first, unknown state changes last
+		// recent   => Most recent state change first, unknown state changes last
+		// count    => Most affected hosts first
+		// hostname => Alphabetically by host name, aggregated rows by their alphabetically first host
+		// priority => By the custom var defined in PRIORITY_VAR, e.g. P1 before P2 before P10
+		// weight   => Highest weight first, see SEVERITY_WEIGHTS
+		// Records that are equal in the chosen order are sorted by severity.
+		// This value can be overwritten by the query parameter "sort" when opening the dashboard in a browser.
+		"SORT": "severity",
+
+		// Name of the custom var holding the priority of a host or service, used by SORT=priority.
+		"PRIORITY_VAR": "priority",
+
+		// Weights used to compute the severity of a service, as comma separated <key>=<weight> rules.
+		// The weights of all matching rules are summed up.
+		// Possible keys
+		// state.<state>       => e.g. state.2=30 for critical services
+		// statetype.<type>    => e.g. statetype.1=10 for hard states
+		// vars.<name>.<value> => e.g. vars.priority.P1=40 or vars.business_impact.high=20
+		"SEVERITY_WEIGHTS": "state.1=10,state.2=30,state.3=20",
+
+		// Comma separated, ascending minimum weights at which rows get colored by their weight
+		// instead of their state, e.g. "40,60" colors rows with weight-1 and weight-2.
+		// At most 4 thresholds are supported.
+		// Leave empty to always color rows by state.
+		"SEVERITY_THRESHOLDS": "",
+
+		// Directory containing files that replace the ones compiled into the binary,
+		// e.g. a custom index.html or assets/style.css. Files missing there are taken from the binary.
+		"OVERRIDE_DIR": "",
+
+		// Look of the dashboard.
+		// Built in themes
+		// default       => Large rows for TV screens
+		// dark          => Dark colors for dimmed rooms
+		// high-contrast => Colorblind safe palette, states marked with symbols and stripes
+		// compact       => Small rows for laptop screens
+		// Custom themes can be added to the override directory as themes/<name>/theme.json.
+		// This value can be overwritten by the query parameter "theme" when opening the dashboard in a browser.
+		"THEME": "default",
+
+		// Language of the dashboard labels if the browser doesn't ask for a supported one.
+		// Possible values: en, de, es
+		// Custom languages can be added to the override directory as locales/<language>.json, e.g. locales/pt-BR.json.
+		// Language tags are case-insensitive.
+		// This value can be overwritten by the query parameter "lang" or the Accept-Language header.
+		"DEFAULT_LANGUAGE": "en",
+
+		// How to deal with more problems than fit on a screen.
+		// Possible values
+		// off      => Show all rows, the page can be scrolled
+		// fit      => Scale all rows down until they fit on the screen
+		// paginate => Split the rows into pages of KIOSK_PAGE_SIZE rows and show each for KIOSK_PAGE_SECONDS seconds
+		// This value can be overwritten by the query parameter "kiosk" when opening the dashboard in a browser.
+		"KIOSK_MODE": "off",
+
+		// Number of rows per page when KIOSK_MODE is paginate.
+		// This value can be overwritten by the query parameter "pageSize" when opening the dashboard in a browser.
+		"KIOSK_PAGE_SIZE": 15,
+
+		// Seconds each page is shown when KIOSK_MODE is paginate.
+		// This value can be overwritten by the query parameter "pageSeconds" when opening the dashboard in a browser.
+		"KIOSK_PAGE_SECONDS": 10,
+
+		// Maximum number of rows shown on the dashboard, the remaining problems are
+		// summarized in a single row like "+12 more warnings". 0 means unlimited.
+		// This value can be overwritten by the query parameter "maxRows" when opening the dashboard in a browser.
+		"MAX_ROWS": 0,
+
+		// Timezone of the clock in the info bar, e.g. "Europe/Berlin". Empty means the server's local time.
+		// This value can be overwritten by the query parameter "tz", e.g. in a view.
+		"TIMEZONE": "",
+
+		// Additional clocks shown in the info bar as a comma separated list of timezones.
+		// Timezones can be labeled, e.g. "UTC,NYC=America/New_York".
+		// This value can be overwritten by the query parameter "clocks".
+		"CLOCKS": "",
+
+		// Show the date next to the time in the info bar (0 = false, 1 = true).
+		// This value can be overwritten by the query parameter "date".
+		"SHOW_DATE": 0,
+
+		// Zones whose replay log lags at least this many seconds behind are shown as cluster problems.
+		// 0 disables the replay log check, disconnected zones and endpoints are always shown.
+		"CLUSTER_LOG_LAG_SECONDS": 300,
+
+		// Hosts and services whose last check is older than this many times their check interval
+		// are listed as stale, e.g. because their check executor died. 0 disables the stale panel.
+		"STALE_FACTOR": 3,
+
+		// Show a banner if Icinga2 started less than this many seconds ago, e.g. because the master restarted.
+		// 0 disables the banner.
+		"RESTART_BANNER_SECONDS": 300,
+
+		// Show a footer with the Icinga2 version, node name, environment, PID and uptime (0 = false, 1 = true).
+		// This value can be overwritten by the query parameter "footer".
+		"SHOW_FOOTER": 0,
+
+		// Comma separated statistics of Icinga2 shown in the info bar, e.g. "avg_latency,active_checks".
+		// Possible values
+		// avg_latency, max_latency               => Check latency in seconds
+		// avg_execution_time, max_execution_time => Check execution time in seconds
+		// active_checks, passive_checks          => Checks within the last minute
+		// concurrent_checks, remote_check_queue  => Checks running and waiting for a satellite or agent
+		// hosts_unreachable, hosts_pending, hosts_flapping, hosts_in_downtime, hosts_acknowledged
+		// services_unreachable, services_pending, services_flapping, services_in_downtime,
+		// services_acknowledged, services_handled
+		// hosts_unhandled, services_unhandled => Problems neither acknowledged, in a downtime nor unreachable
+		// hosts_handled_percent, services_handled_percent => Share of the problems that are handled
+		// This value can be overwritten by the query parameter "widgets".
+		"INFO_BAR_WIDGETS": "",
+
+		// Seconds between the snapshots compared for the feed of problem changes at /feed.atom
+		// and for webhooks. The snapshots use the defaults configured here. 0 disables both.
+		"SNAPSHOT_SECONDS": 60,
+
+		// Number of problem changes listed in the feed.
+		"FEED_SIZE": 100,
+
+		// Path to a JSON file with webhooks to call on board-level events, see README.md.
+		"WEBHOOKS_FILE": "",
+
+		// Seconds a critical service or a host that is down is marked as NEW after it appeared.
+		"ALERT_NEW_SECONDS": 60,
+
+		// Play a sound when a new critical problem appears.
+		// Sounds can be muted per dashboard or view with the query parameter "mute=1".
+		// Possible values
+		// 0 => Disabled
+		// 1 => Enabled
+		"ALERT_SOUND": 1,
+
+		// URL of the sound to play, e.g. /assets/alert.mp3 from the override directory.
+		// A short beep is played if empty.
+		"ALERT_SOUND_URL": "",
+
+		// Flash the screen when a new critical problem appears.
+		// Possible values
+		// 0 => Disabled
+		// 1 => Enabled
+		"ALERT_FLASH": 1,
+
+		// Daily time range without sounds in the timezone of the dashboard (TIMEZONE or "tz"), e.g. "22:00-07:00".
+		"ALERT_QUIET_HOURS": "",
+
+		// Path of a JSON file defining views and kiosk playlists, see README.md.
+		// A view is opened with the query parameter "view", a playlist via /kiosk?playlist=<name>.
+		"VIEWS_FILE": "",
+
+		// Read templates and assets from the working directory instead of the binary,
+		// and reload templates whenever they change.
+		// Possible values
+		// 0 => Disabled
+		// 1 => Enabled
+		"DEV_MODE": 0,
 	}
 
 	// Create a map to store the retrieved values
@@ -302,7 +848,7 @@ func parseEnvVariables() map[string]interface{} {
 				if intValue, err := strconv.Atoi(value); err == nil {
 					envValues[varName] = intValue
 				} else {
-					fmt.Printf("Error parsing %s: %s (using default value)\n", varName, value)
+					fmt.Fprintf(logOutput, "Error parsing %s: %s (using default value)\n", varName, value)
 					envValues[varName] = defaultValue
 				}
 			} else {
@@ -336,6 +882,19 @@ func stateNumToString(state int) string {
 	return mapping[state]
 }
 
+func hostStateNumToString(state int) string {
+	mapping := []string{
+		"Up",
+		"Down",
+	}
+
+	if state < 0 || state >= len(mapping) {
+		return "---"
+	}
+
+	return mapping[state]
+}
+
 func stateTypeNumToString(stateType int) string {
 	mapping := []string{
 		"Soft",
@@ -13,13 +13,33 @@ import (
 )
 
 type stubDashboardClient struct {
-	appStatus *icinga2apiclient.IcingaApplication
-	appErr    error
-	cibStatus *icinga2apiclient.CIBStatus
-	cibErr    error
-	services  []icinga2apiclient.Service
-	hosts     []icinga2apiclient.Host
-	hostsErr  error
+	appStatus   *icinga2apiclient.IcingaApplication
+	appErr      error
+	cibStatus   *icinga2apiclient.CIBStatus
+	cibErr      error
+	services    []icinga2apiclient.Service
+	servicesErr error
+	// Counts the calls of GetServices, if set
+	serviceCalls *int
+	hosts        []icinga2apiclient.Host
+	hostsErr     error
+	deps         []icinga2apiclient.Dependency
+	apiStatus    *icinga2apiclient.APIListenerStatus
+	endpoints    []icinga2apiclient.Endpoint
+	zones        []icinga2apiclient.Zone
+	stale        []icinga2apiclient.Service
+	staleHost    []icinga2apiclient.Host
+	// Records the actions called, the stub is passed by value
+	actions   *[]stubAction
+	actionErr error
+}
+
+type stubAction struct {
+	Name    string
+	Target  icinga2apiclient.ActionTarget
+	Comment string
+	Start   time.Time
+	End     time.Time
 }
 
 func (s stubDashboardClient) GetIcingaApplicationStatus() (*icinga2apiclient.IcingaApplication, error) {
@@ -31,13 +51,54 @@ func (s stubDashboardClient) GetCIBStatus() (*icinga2apiclient.CIBStatus, error)
 }
 
 func (s stubDashboardClient) GetServices(minState int, maxState int, minStateType int) ([]icinga2apiclient.Service, error) {
-	return s.services, nil
+	if s.serviceCalls != nil {
+		*s.serviceCalls++
+	}
+	return s.services, s.servicesErr
 }
 
 func (s stubDashboardClient) GetHosts(minStateType int) ([]icinga2apiclient.Host, error) {
 	return s.hosts, s.hostsErr
 }
 
+func (s stubDashboardClient) GetDependencies() ([]icinga2apiclient.Dependency, error) {
+	return s.deps, nil
+}
+
+func (s stubDashboardClient) GetAPIListenerStatus() (*icinga2apiclient.APIListenerStatus, error) {
+	return s.apiStatus, nil
+}
+
+func (s stubDashboardClient) GetEndpoints() ([]icinga2apiclient.Endpoint, error) {
+	return s.endpoints, nil
+}
+
+func (s stubDashboardClient) GetZones() ([]icinga2apiclient.Zone, error) {
+	return s.zones, nil
+}
+
+func (s stubDashboardClient) GetStaleHosts(factor int) ([]icinga2apiclient.Host, error) {
+	return s.staleHost, nil
+}
+
+func (s stubDashboardClient) GetStaleServices(factor int) ([]icinga2apiclient.Service, error) {
+	return s.stale, nil
+}
+
+func (s stubDashboardClient) AcknowledgeProblem(target icinga2apiclient.ActionTarget, author string, comment string) error {
+	if s.actions != nil {
+		*s.actions = append(*s.actions, stubAction{Name: "acknowledge", Target: target, Comment: comment})
+	}
+	return s.actionErr
+}
+
+func (s stubDashboardClient) ScheduleDowntime(target icinga2apiclient.ActionTarget, author string, comment string, start time.Time, end time.Time) error {
+	if s.actions != nil {
+		*s.actions = append(*s.actions, stubAction{Name: "downtime", Target: target, Comment: comment, Start: start, End: end})
+	}
+	return s.actionErr
+}
+
 func TestBuildServiceListRecords(t *testing.T) {
 	services := []icinga2apiclient.Service{
 		{HostName: "host-b", ServiceName: "disk", State: 2, StateType: 1},
@@ -45,7 +106,7 @@ func TestBuildServiceListRecords(t *testing.T) {
 		{HostName: "host-c", ServiceName: "ping", State: 1, StateType: 0},
 	}
 
-	records := buildServiceListRecords(services)
+	records := buildServiceListRecords(services, serviceGrouping{By: groupByService, Threshold: 2})
 	sort.Sort(ByState(records))
 
 	if len(records) != 2 {
@@ -90,11 +151,75 @@ func TestBuildServiceListRecords(t *testing.T) {
 	}
 }
 
+func TestBuildHostListRecordsCollapsesUnreachableHosts(t *testing.T) {
+	hosts := []icinga2apiclient.Host{
+		{Name: "web-1", State: 1, StateType: 1, Unreachable: true},
+		{Name: "core-sw-1", State: 1, StateType: 1},
+		{Name: "access-sw-1", State: 1, StateType: 1, Unreachable: true},
+		{Name: "db-1", State: 1, StateType: 0, Unreachable: true, LastStateChange: time.Unix(1000, 0)},
+		{Name: "orphan-1", State: 1, StateType: 0, Unreachable: true},
+	}
+	dependencies := []icinga2apiclient.Dependency{
+		{ChildHostName: "access-sw-1", ParentHostName: "core-sw-1"},
+		{ChildHostName: "web-1", ParentHostName: "access-sw-1"},
+		{ChildHostName: "db-1", ParentHostName: "core-sw-1"},
+		{ChildHostName: "orphan-1", ParentHostName: "healthy-sw"},
+		// Service dependencies don't affect host reachability
+		{ChildHostName: "core-sw-1", ParentHostName: "web-1", ParentServiceName: "http"},
+	}
+
+	records := buildHostListRecords(hosts, dependencies)
+	sort.Sort(ByName(records))
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d: %+v", len(records), records)
+	}
+	if records[0].Name != "core-sw-1" {
+		t.Fatalf("expected first record to be core-sw-1, got %q", records[0].Name)
+	}
+	expected := []string{"access-sw-1", "db-1", "web-1"}
+	if records[0].UnreachableHostsCount != len(expected) {
+		t.Fatalf("expected %d unreachable hosts, got %v", len(expected), records[0].UnreachableHosts)
+	}
+	for i, host := range expected {
+		if records[0].UnreachableHosts[i] != host {
+			t.Errorf("expected unreachable host %d to be %q, got %q", i, host, records[0].UnreachableHosts[i])
+		}
+	}
+	if member := records[0].UnreachableMembers[1]; member.Name != "db-1" || member.StateType != 0 || member.LastStateChange.Unix() != 1000 {
+		t.Errorf("expected db-1 with its own state, got %+v", member)
+	}
+	if records[1].Name != "orphan-1" || records[1].UnreachableHostsCount != 0 {
+		t.Errorf("expected orphan-1 to stay on its own, got %+v", records[1])
+	}
+}
+
+func TestBuildHostListRecordsCollapsesIntoTopmostUnreachableParent(t *testing.T) {
+	hosts := []icinga2apiclient.Host{
+		{Name: "leaf", State: 1, StateType: 1, Unreachable: true},
+		{Name: "middle", State: 1, StateType: 1, Unreachable: true},
+		{Name: "top", State: 1, StateType: 1, Unreachable: true},
+	}
+	dependencies := []icinga2apiclient.Dependency{
+		{ChildHostName: "leaf", ParentHostName: "middle"},
+		{ChildHostName: "middle", ParentHostName: "top"},
+	}
+
+	records := buildHostListRecords(hosts, dependencies)
+
+	if len(records) != 1 || records[0].Name != "top" || records[0].UnreachableHostsCount != 2 {
+		t.Errorf("expected everything to collapse into top, got %+v", records)
+	}
+}
+
 func TestBuildPageVariables(t *testing.T) {
 	originalClient := client
 	originalMinState := defaultMinState
 	originalMaxState := defaultMaxState
 	originalMinStateType := defaultMinStateType
+	originalGrouping := defaultGrouping
+	originalExpandMode := defaultExpandMode
+	originalSortOrder := defaultSortOrder
 	originalBaseURL := baseURL
 	originalNow := now
 	defer func() {
@@ -102,6 +227,9 @@ func TestBuildPageVariables(t *testing.T) {
 		defaultMinState = originalMinState
 		defaultMaxState = originalMaxState
 		defaultMinStateType = originalMinStateType
+		defaultGrouping = originalGrouping
+		defaultExpandMode = originalExpandMode
+		defaultSortOrder = originalSortOrder
 		baseURL = originalBaseURL
 		now = originalNow
 	}()
@@ -129,13 +257,16 @@ func TestBuildPageVariables(t *testing.T) {
 	defaultMinState = 1
 	defaultMaxState = 2
 	defaultMinStateType = 0
+	defaultGrouping = serviceGrouping{By: groupByService, Threshold: 2}
+	defaultExpandMode = expandNone
+	defaultSortOrder = sortBySeverity
 	baseURL = "https://icinga.example.test"
 	now = func() time.Time {
 		return time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
 	}
 
 	req := httptest.NewRequest(http.MethodGet, "/?minStateType=1&minState=2&maxState=3", nil)
-	page := buildPageVariables(req)
+	page := buildPageVariables(req, nil)
 
 	if page.Error != nil {
 		t.Fatalf("expected no error, got %v", page.Error)
@@ -166,11 +297,41 @@ func TestBuildPageVariables(t *testing.T) {
 	}
 }
 
+func TestBuildPageVariablesFiltersByHostGroup(t *testing.T) {
+	originalClient := client
+	defer func() { client = originalClient }()
+
+	client = stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{EnableNotifications: true},
+		cibStatus: &icinga2apiclient.CIBStatus{},
+		services: []icinga2apiclient.Service{
+			{HostName: "db-1", ServiceName: "mysql", State: 2, StateType: 1, HostGroups: []string{"databases"}},
+			{HostName: "web-1", ServiceName: "http", State: 2, StateType: 1, HostGroups: []string{"web"}},
+		},
+		hosts: []icinga2apiclient.Host{
+			{Name: "db-2", State: 1, StateType: 1, Groups: []string{"databases", "linux"}},
+			{Name: "web-2", State: 1, StateType: 1},
+		},
+	}
+
+	page := buildPageVariables(httptest.NewRequest(http.MethodGet, "/?hostGroup=databases", nil), nil)
+
+	if len(page.ServiceRecords) != 1 || page.ServiceRecords[0].Name != "mysql" {
+		t.Errorf("expected only the mysql service, got %+v", page.ServiceRecords)
+	}
+	if len(page.HostRecords) != 1 || page.HostRecords[0].Name != "db-2" {
+		t.Errorf("expected only host db-2, got %+v", page.HostRecords)
+	}
+}
+
 func TestRenderJSON(t *testing.T) {
 	originalClient := client
 	originalMinState := defaultMinState
 	originalMaxState := defaultMaxState
 	originalMinStateType := defaultMinStateType
+	originalGrouping := defaultGrouping
+	originalExpandMode := defaultExpandMode
+	originalSortOrder := defaultSortOrder
 	originalBaseURL := baseURL
 	originalNow := now
 	defer func() {
@@ -178,6 +339,9 @@ func TestRenderJSON(t *testing.T) {
 		defaultMinState = originalMinState
 		defaultMaxState = originalMaxState
 		defaultMinStateType = originalMinStateType
+		defaultGrouping = originalGrouping
+		defaultExpandMode = originalExpandMode
+		defaultSortOrder = originalSortOrder
 		baseURL = originalBaseURL
 		now = originalNow
 	}()
@@ -189,6 +353,9 @@ func TestRenderJSON(t *testing.T) {
 	defaultMinState = 1
 	defaultMaxState = 2
 	defaultMinStateType = 0
+	defaultGrouping = serviceGrouping{By: groupByService, Threshold: 2}
+	defaultExpandMode = expandNone
+	defaultSortOrder = sortBySeverity
 	baseURL = "https://icinga.example.test"
 	now = func() time.Time {
 		return time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
@@ -219,6 +386,9 @@ func TestRenderDashboardReturnsInternalServerErrorOnDataError(t *testing.T) {
 	originalMinState := defaultMinState
 	originalMaxState := defaultMaxState
 	originalMinStateType := defaultMinStateType
+	originalGrouping := defaultGrouping
+	originalExpandMode := defaultExpandMode
+	originalSortOrder := defaultSortOrder
 	originalBaseURL := baseURL
 	originalNow := now
 	defer func() {
@@ -226,6 +396,9 @@ func TestRenderDashboardReturnsInternalServerErrorOnDataError(t *testing.T) {
 		defaultMinState = originalMinState
 		defaultMaxState = originalMaxState
 		defaultMinStateType = originalMinStateType
+		defaultGrouping = originalGrouping
+		defaultExpandMode = originalExpandMode
+		defaultSortOrder = originalSortOrder
 		baseURL = originalBaseURL
 		now = originalNow
 	}()
@@ -236,6 +409,9 @@ func TestRenderDashboardReturnsInternalServerErrorOnDataError(t *testing.T) {
 	defaultMinState = 1
 	defaultMaxState = 2
 	defaultMinStateType = 0
+	defaultGrouping = serviceGrouping{By: groupByService, Threshold: 2}
+	defaultExpandMode = expandNone
+	defaultSortOrder = sortBySeverity
 	baseURL = "https://icinga.example.test"
 	now = func() time.Time {
 		return time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
@@ -304,3 +480,153 @@ func TestStateTypeNumToString(t *testing.T) {
 		t.Errorf("expected fallback for invalid state type")
 	}
 }
+
+func TestRenderDashboard(t *testing.T) {
+	originalClient := client
+	originalMinState := defaultMinState
+	originalMaxState := defaultMaxState
+	originalMinStateType := defaultMinStateType
+	originalGrouping := defaultGrouping
+	originalExpandMode := defaultExpandMode
+	originalSortOrder := defaultSortOrder
+	originalBaseURL := baseURL
+	originalNow := now
+	defer func() {
+		client = originalClient
+		defaultMinState = originalMinState
+		defaultMaxState = originalMaxState
+		defaultMinStateType = originalMinStateType
+		defaultGrouping = originalGrouping
+		defaultExpandMode = originalExpandMode
+		defaultSortOrder = originalSortOrder
+		baseURL = originalBaseURL
+		now = originalNow
+	}()
+
+	client = stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{EnableNotifications: true},
+		cibStatus: &icinga2apiclient.CIBStatus{},
+		services: []icinga2apiclient.Service{
+			{HostName: "host-a", ServiceName: "disk", State: 2, StateType: 1, HostGroups: []string{"databases"}},
+			{HostName: "host-b", ServiceName: "load", State: 2, StateType: 1, HostGroups: []string{"databases"}},
+		},
+		hosts: []icinga2apiclient.Host{
+			{Name: "core-sw-1", State: 1, StateType: 1},
+			{Name: "web-1", State: 1, StateType: 1, Unreachable: true},
+		},
+		deps: []icinga2apiclient.Dependency{
+			{ChildHostName: "web-1", ParentHostName: "core-sw-1"},
+		},
+	}
+	defaultMinState = 1
+	defaultMaxState = 2
+	defaultMinStateType = 0
+	defaultGrouping = serviceGrouping{By: groupByService, Threshold: 2}
+	defaultExpandMode = expandNone
+	defaultSortOrder = sortBySeverity
+	baseURL = "https://icinga.example.test"
+	now = func() time.Time {
+		return time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?groupBy=hostgroup&expand=all", nil)
+	rec := httptest.NewRecorder()
+
+	renderDashboard(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Down (1 host unreachable)") {
+		t.Errorf("expected collapsed unreachable hosts in rendered dashboard, got %s", body)
+	}
+	if !strings.Contains(body, "databases:") || !strings.Contains(body, "2 Services") {
+		t.Errorf("expected host group aggregation in rendered dashboard, got %s", body)
+	}
+	if !strings.Contains(body, `<a href="https://icinga.example.test/host?name=host-b" target="_blank">host-b</a>`) {
+		t.Errorf("expected expanded host links in rendered dashboard, got %s", body)
+	}
+	if !strings.Contains(body, `<tr class="host-1-1">`) {
+		t.Errorf("expected default state classes in rendered dashboard, got %s", body)
+	}
+}
+
+func TestRenderDashboardWithTheme(t *testing.T) {
+	originalClient := client
+	originalThemes := themes
+	originalNow := now
+	defer func() {
+		client = originalClient
+		themes = originalThemes
+		now = originalNow
+	}()
+
+	client = stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{EnableNotifications: true},
+		cibStatus: &icinga2apiclient.CIBStatus{},
+		hosts: []icinga2apiclient.Host{
+			{Name: "core-sw-1", State: 1, StateType: 1},
+		},
+	}
+	var err error
+	themes, err = loadThemes(embeddedFiles)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	now = func() time.Time {
+		return time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?theme=high-contrast", nil)
+	rec := httptest.NewRecorder()
+
+	renderDashboard(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `href="/themes/high-contrast/style.css"`) {
+		t.Errorf("expected theme stylesheet in rendered dashboard, got %s", body)
+	}
+	if !strings.Contains(body, `<tr class="hc-critical">`) {
+		t.Errorf("expected theme state classes in rendered dashboard, got %s", body)
+	}
+}
+
+func TestRenderDashboardWithThemeWeightClasses(t *testing.T) {
+	withAPITestClient(t, apiTestClient())
+	originalThemes, originalWeighting := themes, weighting
+	defer func() { themes, weighting = originalThemes, originalWeighting }()
+	var err error
+	if themes, err = loadThemes(embeddedFiles); err != nil {
+		t.Fatal(err)
+	}
+	if weighting, err = parseSeverityWeighting("state.2=30", "20"); err != nil {
+		t.Fatal(err)
+	}
+
+	for theme, expected := range map[string]string{
+		"default":       `<tr class="service-2-1 weight-1 aggregated"`,
+		"dark":          `<tr class="service-2-1 dark-weight-1 aggregated"`,
+		"high-contrast": `<tr class="hc-critical hc-weight-1 aggregated"`,
+	} {
+		rec := httptest.NewRecorder()
+		renderDashboard(rec, httptest.NewRequest(http.MethodGet, "/?theme="+theme, nil))
+		if !strings.Contains(rec.Body.String(), expected) {
+			t.Errorf("expected %s in the %s theme, got %s", expected, theme, rec.Body.String())
+		}
+	}
+}
+
+func TestBuildPageVariables_ServicesError(t *testing.T) {
+	stub := apiTestClient()
+	stub.servicesErr = errors.New("connection reset")
+	withAPITestClient(t, stub)
+
+	pageVariables := buildPageVariables(httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	if pageVariables.Error == nil {
+		t.Errorf("expected the error of the service query to fail the page")
+	}
+	if len(pageVariables.ServiceRecords) != 0 {
+		t.Errorf("expected no services, got %+v", pageVariables.ServiceRecords)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sort orders that can be chosen with the "sort" query parameter.
+//
+// Every order falls back to severity when two records are equal, and
+// severity itself falls back to the name. This keeps the dashboard stable
+// between refreshes.
+//
+//	severity => state descending, then name ascending, then state type ascending
+//	duration => longest lasting problem first (unknown state changes last), then severity
+//	recent   => most recent state change first (unknown state changes last), then severity
+//	count    => most affected hosts first, then severity
+//	hostname => host name ascending (aggregated records by their first host), then severity
+//	priority => priority custom var ascending in natural order (records without one last), then severity
+//	weight   => computed weight descending, then severity (hosts have no weight and use severity)
+const (
+	sortBySeverity = "severity"
+	sortByDuration = "duration"
+	sortByRecent   = "recent"
+	sortByCount    = "count"
+	sortByHostName = "hostname"
+	sortByPriority = "priority"
+	sortByWeight   = "weight"
+)
+
+func isValidSortOrder(order string) bool {
+	switch order {
+	case sortBySeverity, sortByDuration, sortByRecent, sortByCount, sortByHostName, sortByPriority, sortByWeight:
+		return true
+	}
+	return false
+}
+
+func sortServiceRecords(records []PageServiceListRecord, order string) {
+	severity := ByState(records)
+	var less func(i, j int) bool
+
+	switch order {
+	case sortByDuration:
+		less = func(i, j int) bool {
+			if !records[i].Since.Equal(records[j].Since.Time) {
+				return lessTime(records[i].Since, records[j].Since, false)
+			}
+			return severity.Less(i, j)
+		}
+	case sortByRecent:
+		less = func(i, j int) bool {
+			if !records[i].LastStateChange.Equal(records[j].LastStateChange.Time) {
+				return lessTime(records[i].LastStateChange, records[j].LastStateChange, true)
+			}
+			return severity.Less(i, j)
+		}
+	case sortByCount:
+		less = func(i, j int) bool {
+			if records[i].AggregatedHostsCount != records[j].AggregatedHostsCount {
+				return records[i].AggregatedHostsCount > records[j].AggregatedHostsCount
+			}
+			return severity.Less(i, j)
+		}
+	case sortByHostName:
+		less = func(i, j int) bool {
+			if hostI, hostJ := firstHost(records[i]), firstHost(records[j]); hostI != hostJ {
+				return hostI < hostJ
+			}
+			return severity.Less(i, j)
+		}
+	case sortByPriority:
+		less = func(i, j int) bool {
+			if records[i].Priority != records[j].Priority {
+				return lessPriority(records[i].Priority, records[j].Priority)
+			}
+			return severity.Less(i, j)
+		}
+	case sortByWeight:
+		less = func(i, j int) bool {
+			if records[i].Weight != records[j].Weight {
+				return records[i].Weight > records[j].Weight
+			}
+			return severity.Less(i, j)
+		}
+	default:
+		less = severity.Less
+	}
+
+	sort.SliceStable(records, less)
+}
+
+func sortHostRecords(records []PageHostListRecord, order string) {
+	severity := func(i, j int) bool {
+		if records[i].State != records[j].State {
+			return records[i].State > records[j].State
+		}
+		if records[i].Name != records[j].Name {
+			return records[i].Name < records[j].Name
+		}
+		return records[i].StateType < records[j].StateType
+	}
+	var less func(i, j int) bool
+
+	switch order {
+	case sortByDuration:
+		less = func(i, j int) bool {
+			if !records[i].LastStateChange.Equal(records[j].LastStateChange.Time) {
+				return lessTime(records[i].LastStateChange, records[j].LastStateChange, false)
+			}
+			return severity(i, j)
+		}
+	case sortByRecent:
+		less = func(i, j int) bool {
+			if !records[i].LastStateChange.Equal(records[j].LastStateChange.Time) {
+				return lessTime(records[i].LastStateChange, records[j].LastStateChange, true)
+			}
+			return severity(i, j)
+		}
+	case sortByCount:
+		// A host affects itself and every host that is unreachable because of it
+		less = func(i, j int) bool {
+			if records[i].UnreachableHostsCount != records[j].UnreachableHostsCount {
+				return records[i].UnreachableHostsCount > records[j].UnreachableHostsCount
+			}
+			return severity(i, j)
+		}
+	case sortByHostName:
+		less = ByName(records).Less
+	case sortByPriority:
+		less = func(i, j int) bool {
+			if records[i].Priority != records[j].Priority {
+				return lessPriority(records[i].Priority, records[j].Priority)
+			}
+			return severity(i, j)
+		}
+	default:
+		less = severity
+	}
+
+	sort.SliceStable(records, less)
+}
+
+// firstHost returns the alphabetically first host of a record, so aggregated
+// records sort among the others instead of by their "3 Hosts" label.
+func firstHost(record PageServiceListRecord) string {
+	if len(record.AggregatedHosts) == 0 {
+		return record.HostField
+	}
+	return slices.Min(record.AggregatedHosts)
+}
+
+// lessTime orders oldest or newest first. Unknown times, e.g. of checks that
+// never ran, are sorted last in both directions.
+func lessTime(a timestamp, b timestamp, newestFirst bool) bool {
+	if a.IsZero() || b.IsZero() {
+		return b.IsZero() && !a.IsZero()
+	}
+	if newestFirst {
+		return a.After(b.Time)
+	}
+	return a.Before(b.Time)
+}
+
+// lessPriority orders priorities ascending, so "P1" comes before "P2" and 1
+// before 2. Numbers are compared numerically, also within text, so "P2" comes
+// before "P10". Records without a priority are sorted last.
+func lessPriority(a string, b string) bool {
+	if a == "" || b == "" {
+		return b == ""
+	}
+	numA, errA := strconv.ParseFloat(a, 64)
+	numB, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return numA < numB
+	}
+	return naturalLess(a, b)
+}
+
+// naturalLess compares runs of digits by their value and everything else
+// byte by byte.
+func naturalLess(a string, b string) bool {
+	for a != "" && b != "" {
+		chunkA, restA := leadingChunk(a)
+		chunkB, restB := leadingChunk(b)
+		if chunkA != chunkB {
+			if isDigit(chunkA[0]) && isDigit(chunkB[0]) {
+				numA, numB := strings.TrimLeft(chunkA, "0"), strings.TrimLeft(chunkB, "0")
+				if len(numA) != len(numB) {
+					return len(numA) < len(numB)
+				}
+				if numA != numB {
+					return numA < numB
+				}
+			}
+			return chunkA < chunkB
+		}
+		a, b = restA, restB
+	}
+	return len(a) < len(b)
+}
+
+// leadingChunk splits off the leading run of digits or of other characters.
+func leadingChunk(s string) (string, string) {
+	digits := isDigit(s[0])
+	end := 1
+	for end < len(s) && isDigit(s[end]) == digits {
+		end++
+	}
+	return s[:end], s[end:]
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func at(seconds int64) timestamp {
+	return timestamp{time.Unix(seconds, 0)}
+}
+
+func serviceNames(records []PageServiceListRecord) []string {
+	var names []string
+	for _, record := range records {
+		names = append(names, record.Name)
+	}
+	return names
+}
+
+func hostNames(records []PageHostListRecord) []string {
+	var names []string
+	for _, record := range records {
+		names = append(names, record.Name)
+	}
+	return names
+}
+
+func assertOrder(t *testing.T, got []string, expected []string) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("got %v, want %v", got, expected)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("got %v, want %v", got, expected)
+			return
+		}
+	}
+}
+
+func TestIsValidSortOrder(t *testing.T) {
+	for _, order := range []string{"severity", "duration", "recent", "count", "hostname", "priority", "weight"} {
+		if !isValidSortOrder(order) {
+			t.Errorf("expected %q to be valid", order)
+		}
+	}
+	if isValidSortOrder("random") {
+		t.Errorf("expected random to be invalid")
+	}
+}
+
+func TestSortServiceRecords(t *testing.T) {
+	newRecords := func() []PageServiceListRecord {
+		return []PageServiceListRecord{
+			{Name: "A", HostField: "web-1", State: 1, Since: at(100), LastStateChange: at(100), AggregatedHostsCount: 1, Priority: "P2", Weight: 10},
+			{Name: "B", HostField: "2 Hosts", State: 2, Since: at(50), LastStateChange: at(300), AggregatedHosts: []string{"web-9", "web-2"}, AggregatedHostsCount: 2, Weight: 30},
+			{Name: "C", HostField: "db-1", State: 2, Since: at(200), LastStateChange: at(200), AggregatedHostsCount: 1, Priority: "P1", Weight: 70},
+			{Name: "D", HostField: "db-1", State: 1, Since: at(100), LastStateChange: at(100), AggregatedHostsCount: 1, Priority: "P2", Weight: 30},
+		}
+	}
+
+	tests := []struct {
+		order    string
+		expected []string
+	}{
+		// State descending, then name
+		{sortBySeverity, []string{"B", "C", "A", "D"}},
+		// Oldest first, A and D tie and fall back to severity
+		{sortByDuration, []string{"B", "A", "D", "C"}},
+		// Newest first
+		{sortByRecent, []string{"B", "C", "A", "D"}},
+		// Most hosts first, the rest by severity
+		{sortByCount, []string{"B", "C", "A", "D"}},
+		// Host name ascending, both db-1 records by severity, B by its first host web-2
+		{sortByHostName, []string{"C", "D", "A", "B"}},
+		// P1 first, records without priority last
+		{sortByPriority, []string{"C", "A", "D", "B"}},
+		// Highest weight first, B and D tie and fall back to severity
+		{sortByWeight, []string{"C", "B", "D", "A"}},
+		// Unknown orders fall back to severity
+		{"", []string{"B", "C", "A", "D"}},
+	}
+
+	for _, tt := range tests {
+		records := newRecords()
+		sortServiceRecords(records, tt.order)
+		t.Run(tt.order, func(t *testing.T) {
+			assertOrder(t, serviceNames(records), tt.expected)
+		})
+	}
+}
+
+func TestSortHostRecords(t *testing.T) {
+	newRecords := func() []PageHostListRecord {
+		return []PageHostListRecord{
+			{Name: "gamma", State: 1, StateType: 1, LastStateChange: at(300)},
+			{Name: "alpha", State: 1, StateType: 0, LastStateChange: at(100), Priority: "2"},
+			{Name: "beta", State: 1, StateType: 1, LastStateChange: at(100), UnreachableHostsCount: 3, Priority: "10"},
+		}
+	}
+
+	tests := []struct {
+		order    string
+		expected []string
+	}{
+		{sortBySeverity, []string{"alpha", "beta", "gamma"}},
+		{sortByDuration, []string{"alpha", "beta", "gamma"}},
+		{sortByRecent, []string{"gamma", "alpha", "beta"}},
+		{sortByCount, []string{"beta", "alpha", "gamma"}},
+		{sortByHostName, []string{"alpha", "beta", "gamma"}},
+		// Numeric priorities are compared as numbers
+		{sortByPriority, []string{"alpha", "beta", "gamma"}},
+	}
+
+	for _, tt := range tests {
+		records := newRecords()
+		sortHostRecords(records, tt.order)
+		t.Run(tt.order, func(t *testing.T) {
+			assertOrder(t, hostNames(records), tt.expected)
+		})
+	}
+}
+
+func TestSortUnknownStateChangesLast(t *testing.T) {
+	for _, order := range []string{sortByDuration, sortByRecent} {
+		hosts := []PageHostListRecord{
+			{Name: "alpha", State: 1},
+			{Name: "beta", State: 1, LastStateChange: at(100)},
+			{Name: "gamma", State: 1, LastStateChange: at(200)},
+		}
+		sortHostRecords(hosts, order)
+		if hosts[2].Name != "alpha" {
+			t.Errorf("%s: expected the host without state change last, got %v", order, hostNames(hosts))
+		}
+
+		services := []PageServiceListRecord{
+			{Name: "A", State: 2},
+			{Name: "B", State: 2, Since: at(100), LastStateChange: at(100)},
+			{Name: "C", State: 2, Since: at(200), LastStateChange: at(200)},
+		}
+		sortServiceRecords(services, order)
+		if services[2].Name != "A" {
+			t.Errorf("%s: expected the service without state change last, got %v", order, serviceNames(services))
+		}
+	}
+}
+
+func TestLessPriority(t *testing.T) {
+	if !lessPriority("P1", "P2") {
+		t.Errorf("expected P1 before P2")
+	}
+	if !lessPriority("2", "10") {
+		t.Errorf("expected 2 before 10")
+	}
+	if !lessPriority("P2", "P10") || lessPriority("P10", "P2") {
+		t.Errorf("expected P2 before P10")
+	}
+	if !lessPriority("P2a", "P2b") || !lessPriority("P02", "P3") || !lessPriority("P", "P1") {
+		t.Errorf("expected natural order of mixed priorities")
+	}
+	if !lessPriority("P4", "") {
+		t.Errorf("expected records without priority last")
+	}
+	if lessPriority("", "P4") {
+		t.Errorf("expected records without priority last")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+// Number of stale checks listed in the stale panel, the count includes all of them
+const stalePanelRows = 10
+
+// PageStaleRecord is a host or service whose checks stopped running.
+// Service is empty for hosts.
+type PageStaleRecord struct {
+	Host      string    `json:"host"`
+	Service   string    `json:"service"`
+	LastCheck timestamp `json:"last_check"`
+	// Seconds
+	CheckInterval int `json:"check_interval"`
+	// Seconds since the last check, left out of the JSON API so ETags only change with the last check
+	Age int `json:"-"`
+}
+
+func (r PageStaleRecord) AgeString() string {
+	return formatDuration(time.Duration(r.Age) * time.Second)
+}
+
+func (r PageStaleRecord) CheckIntervalString() string {
+	return formatDuration(time.Duration(r.CheckInterval) * time.Second)
+}
+
+// getStaleRecords fetches the hosts and services whose last check is older
+// than factor times their check interval, the oldest first. Checks disabled
+// globally are not stale, they have their own banner.
+func getStaleRecords(client dashboardClient, factor int, hostGroup string, disabled []string, currentTime time.Time) []PageStaleRecord {
+	if factor <= 0 {
+		return nil
+	}
+
+	var hosts []icinga2apiclient.Host
+	if !slices.Contains(disabled, featureHostChecks) {
+		var err error
+		hosts, err = client.GetStaleHosts(factor)
+		if err != nil {
+			fmt.Fprintf(logOutput, "Error getting stale hosts: %v\n", err)
+		}
+	}
+	var services []icinga2apiclient.Service
+	if !slices.Contains(disabled, featureServiceChecks) {
+		var err error
+		services, err = client.GetStaleServices(factor)
+		if err != nil {
+			fmt.Fprintf(logOutput, "Error getting stale services: %v\n", err)
+		}
+	}
+
+	var records []PageStaleRecord
+	for _, host := range hosts {
+		if hostGroup != "" && !slices.Contains(host.Groups, hostGroup) {
+			continue
+		}
+		records = append(records, newStaleRecord(host.Name, "", host.LastCheck, host.CheckInterval, currentTime))
+	}
+	for _, service := range services {
+		if hostGroup != "" && !slices.Contains(service.HostGroups, hostGroup) {
+			continue
+		}
+		records = append(records, newStaleRecord(service.HostName, service.ServiceName, service.LastCheck, service.CheckInterval, currentTime))
+	}
+
+	slices.SortFunc(records, func(a, b PageStaleRecord) int {
+		return cmp.Or(
+			cmp.Compare(b.Age, a.Age),
+			cmp.Compare(a.Host, b.Host),
+			cmp.Compare(a.Service, b.Service),
+		)
+	})
+	return records
+}
+
+func newStaleRecord(host string, service string, lastCheck time.Time, checkInterval time.Duration, currentTime time.Time) PageStaleRecord {
+	return PageStaleRecord{
+		Host:          host,
+		Service:       service,
+		LastCheck:     timestamp{lastCheck},
+		CheckInterval: int(checkInterval.Seconds()),
+		Age:           int(currentTime.Sub(lastCheck).Seconds()),
+	}
+}
+
+// StalePanel returns the stale checks shown in the stale panel.
+func (p PageVariables) StalePanel() []PageStaleRecord {
+	return p.StaleRecords[:min(len(p.StaleRecords), stalePanelRows)]
+}
+
+// StaleOverflow returns the number of stale checks not shown in the stale panel.
+func (p PageVariables) StaleOverflow() int {
+	return max(len(p.StaleRecords)-stalePanelRows, 0)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func staleTestClient() stubDashboardClient {
+	currentTime := time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
+	return stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{
+			EnableNotifications: true, EnableHostChecks: true, EnableServiceChecks: true,
+			Version: "r2.14.2-1", ProgramStart: float64(time.Date(2026, time.March, 1, 12, 30, 0, 0, time.UTC).Unix()),
+		},
+		cibStatus: &icinga2apiclient.CIBStatus{},
+		staleHost: []icinga2apiclient.Host{
+			{Name: "db-1", Groups: []string{"databases"}, LastCheck: currentTime.Add(-20 * time.Minute), CheckInterval: 5 * time.Minute},
+		},
+		stale: []icinga2apiclient.Service{
+			{HostName: "web-1", ServiceName: "http", HostGroups: []string{"web"}, LastCheck: currentTime.Add(-3 * time.Hour), CheckInterval: time.Minute},
+			{HostName: "db-1", ServiceName: "mysql", HostGroups: []string{"databases"}, LastCheck: currentTime.Add(-20 * time.Minute), CheckInterval: time.Minute},
+		},
+	}
+}
+
+func TestGetStaleRecords(t *testing.T) {
+	stub := staleTestClient()
+	currentTime := time.Date(2026, time.March, 11, 8, 9, 10, 0, time.UTC)
+
+	records := getStaleRecords(stub, 3, "", nil, currentTime)
+	var got []string
+	for _, record := range records {
+		got = append(got, record.Host+"!"+record.Service+"!"+record.AgeString()+"!"+record.CheckIntervalString())
+	}
+	// Oldest first, hosts before their services on equal age
+	want := []string{"web-1!http!3h 0m!1m", "db-1!!20m!5m", "db-1!mysql!20m!1m"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	records = getStaleRecords(stub, 3, "databases", []string{featureHostChecks}, currentTime)
+	if len(records) != 1 || records[0].Service != "mysql" {
+		t.Errorf("expected only the mysql service, got %+v", records)
+	}
+
+	if records := getStaleRecords(stub, 0, "", nil, currentTime); records != nil {
+		t.Errorf("expected no records with STALE_FACTOR=0, got %+v", records)
+	}
+}
+
+func TestStalePanel(t *testing.T) {
+	pageVariables := PageVariables{StaleRecords: make([]PageStaleRecord, stalePanelRows+3)}
+	if len(pageVariables.StalePanel()) != stalePanelRows || pageVariables.StaleOverflow() != 3 {
+		t.Errorf("expected %d rows and 3 more, got %d and %d", stalePanelRows, len(pageVariables.StalePanel()), pageVariables.StaleOverflow())
+	}
+
+	pageVariables = PageVariables{StaleRecords: make([]PageStaleRecord, 2)}
+	if len(pageVariables.StalePanel()) != 2 || pageVariables.StaleOverflow() != 0 {
+		t.Errorf("expected 2 rows and none more, got %d and %d", len(pageVariables.StalePanel()), pageVariables.StaleOverflow())
+	}
+}
+
+func TestRenderDashboardShowsStaleChecks(t *testing.T) {
+	withAPITestClient(t, staleTestClient())
+	originalFactor := staleFactor
+	staleFactor = 3
+	defer func() { staleFactor = originalFactor }()
+
+	rec := httptest.NewRecorder()
+	renderDashboard(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	body := rec.Body.String()
+	for _, expected := range []string{
+		"Stale checks (3)",
+		"last check 3h 0m ago, every 1m",
+		`<td class="stats stats-value stale-count">3</td>`,
+		"Icinga2 r2.14.2-1, running since 2026-03-01 12:30:00",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in dashboard, got %s", expected, body)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	renderJSON(rec, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard?fields=stale_count", nil))
+	if !strings.Contains(rec.Body.String(), `"stale_count":3`) {
+		t.Errorf("expected stale count in JSON, got %s", rec.Body.String())
+	}
+}
@@ -16,7 +16,7 @@ set -euo pipefail
 #   - Adjust ICINGAWEBURL, APIURL, etc. in the script
 #
 # Output:
-#   - Status line with count of critical and warning services/hosts
+#   - Status line with count of critical and warning services/hosts and globally disabled features
 #   - Details with links to IcingaWeb2
 #
 # Errors are printed to STDERR, exit code 1 on error.
@@ -30,7 +30,11 @@ readonly ICINGAWEBURL=icinga2.example.com
 readonly ICINGAWEBLINK=https://icinga2.example.com/
 
 # Endpoint of the Icinga-Dashbaord API.
-readonly APIURL="https://icinga2-dashboard.example.com/api/v1/dashboard"
+readonly APIURL="https://icinga2-dashboard.example.com/api/v1/dashboard?fields=hosts,services,disabled_features"
+
+# Last response and its ETag, so unchanged snapshots are not transferred again
+readonly CACHEFILE="${TMPDIR:-/tmp}/icinga-dashboard.json"
+readonly ETAGFILE="${TMPDIR:-/tmp}/icinga-dashboard.etag"
 
 # Location of the curl binary
 readonly CURLPATH=/usr/bin/curl
@@ -157,20 +161,32 @@ print_separator() {
 if ! /sbin/ping -c 1 "$ICINGAWEBURL" &>/dev/null; then
 	echo "🔌 NO VPN $STYLE_RED"
 else
-	dashboard_json=$($CURLPATH --silent --fail "$APIURL")
-	if [ $? -ne 0 ] || [ -z "$dashboard_json" ]; then
+	[ -s "$CACHEFILE" ] || rm -f "$ETAGFILE"
+	status=$($CURLPATH --silent --fail --output "$CACHEFILE.new" --write-out "%{http_code}" \
+		--etag-compare "$ETAGFILE" --etag-save "$ETAGFILE" "$APIURL")
+	if [ $? -ne 0 ]; then
+		error_exit "Failed to fetch dashboard data!"
+	fi
+	if [ "$status" != "304" ]; then
+		mv "$CACHEFILE.new" "$CACHEFILE"
+	fi
+	rm -f "$CACHEFILE.new"
+	dashboard_json=$(cat "$CACHEFILE")
+	if [ -z "$dashboard_json" ]; then
 		error_exit "Failed to fetch dashboard data!"
 	fi
 	service_warn=$(echo "$dashboard_json" | $JQPATH '.services | map(select(.state==1 and .state_type==1))')
 	service_crit=$(echo "$dashboard_json" | $JQPATH '.services | map(select(.state==2 and .state_type==1))')
 	host_crit=$(echo "$dashboard_json" | $JQPATH '.hosts | map(select(.state==1 and .state_type==1))')
+	disabled_features=$(echo "$dashboard_json" | $JQPATH '.disabled_features // []')
 
 	# Calculate warn and crit counts using functions
 	local_warn=$(count_services "$service_warn")
 	local_crit=$(count_services "$service_crit")
 	local_host_crit=$(echo "$host_crit" | $JQPATH 'length')
+	local_features=$(echo "$disabled_features" | $JQPATH 'length')
 	warn=$local_warn
-	crit=$((local_crit + local_host_crit))
+	crit=$((local_crit + local_host_crit + local_features))
 
 	print_status_line "$crit" "$warn"
 
@@ -180,6 +196,9 @@ else
 	if [ "$crit" -gt 0 ]; then
 		echo "---"
 		echo "🛑 $crit  $STYLE_RED"
+		echo "$disabled_features" | $JQPATH -r '.[]' | while read -r feature; do
+			echo "🛑 Disabled globally: $feature"
+		done
 		echo "$host_crit" | $JQPATH -r '.[] | [.name] | @tsv' | while IFS=$'\t' read -r host; do
 			print_host "🛑" "$host"
 		done
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+const defaultThemeName = "default"
+
+// theme changes the look of the dashboard. Themes live in themes/<name>/theme.json,
+// either compiled into the binary or in the override directory.
+type theme struct {
+	Name string `json:"-"`
+	// Stylesheets are relative to the theme directory and loaded after assets/style.css
+	Stylesheets []string `json:"stylesheets"`
+	// Map "<state>-<state_type>" to CSS classes. States without a mapping
+	// use host-<state>-<state_type> and service-<state>-<state_type>.
+	HostClasses    map[string]string `json:"host_classes"`
+	ServiceClasses map[string]string `json:"service_classes"`
+	// Map weight levels like "1" to CSS classes, see SEVERITY_THRESHOLDS.
+	// Levels without a mapping use weight-<level>.
+	WeightClasses map[string]string `json:"weight_classes"`
+}
+
+func (t *theme) HostClass(state int, stateType int) string {
+	if class, ok := t.HostClasses[fmt.Sprintf("%d-%d", state, stateType)]; ok {
+		return class
+	}
+	return fmt.Sprintf("host-%d-%d", state, stateType)
+}
+
+func (t *theme) ServiceClass(state int, stateType int) string {
+	if class, ok := t.ServiceClasses[fmt.Sprintf("%d-%d", state, stateType)]; ok {
+		return class
+	}
+	return fmt.Sprintf("service-%d-%d", state, stateType)
+}
+
+func (t *theme) WeightClass(level int) string {
+	if class, ok := t.WeightClasses[fmt.Sprintf("%d", level)]; ok {
+		return class
+	}
+	return fmt.Sprintf("weight-%d", level)
+}
+
+func (t *theme) StylesheetURLs() []string {
+	var urls []string
+	for _, stylesheet := range t.Stylesheets {
+		urls = append(urls, path.Join("/themes", t.Name, stylesheet))
+	}
+	return urls
+}
+
+// loadThemes reads all themes/<name>/theme.json files.
+func loadThemes(fsys fs.FS) (map[string]*theme, error) {
+	themes := make(map[string]*theme)
+
+	names, err := fs.Glob(fsys, "themes/*/theme.json")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var loaded theme
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %w", name, err)
+		}
+		loaded.Name = path.Base(path.Dir(name))
+		themes[loaded.Name] = &loaded
+	}
+
+	return themes, nil
+}
+
+// selectTheme returns the theme with the given name, falling back to the
+// configured default theme and finally to the plain built in look.
+func selectTheme(name string) *theme {
+	if selected, ok := themes[name]; ok {
+		return selected
+	}
+	if selected, ok := themes[defaultTheme]; ok {
+		return selected
+	}
+	return &theme{Name: defaultThemeName}
+}
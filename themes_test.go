@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadThemes_Embedded(t *testing.T) {
+	loaded, err := loadThemes(embeddedFiles)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, name := range []string{"default", "dark", "high-contrast", "compact"} {
+		if _, ok := loaded[name]; !ok {
+			t.Errorf("expected built in theme %q", name)
+		}
+	}
+	if urls := loaded["dark"].StylesheetURLs(); len(urls) != 1 || urls[0] != "/themes/dark/style.css" {
+		t.Errorf("unexpected stylesheets for dark theme: %v", urls)
+	}
+}
+
+func TestLoadThemes_InvalidJSON(t *testing.T) {
+	files := fstest.MapFS{
+		"themes/broken/theme.json": {Data: []byte("{")},
+	}
+	if _, err := loadThemes(files); err == nil {
+		t.Errorf("expected error for invalid theme definition")
+	}
+}
+
+func TestThemeClasses(t *testing.T) {
+	custom := &theme{
+		HostClasses:    map[string]string{"1-1": "down"},
+		ServiceClasses: map[string]string{"2-1": "critical blink"},
+	}
+
+	if custom.HostClass(1, 1) != "down" {
+		t.Errorf("expected mapped host class, got %q", custom.HostClass(1, 1))
+	}
+	if custom.HostClass(1, 0) != "host-1-0" {
+		t.Errorf("expected default host class, got %q", custom.HostClass(1, 0))
+	}
+	if custom.ServiceClass(2, 1) != "critical blink" {
+		t.Errorf("expected mapped service class, got %q", custom.ServiceClass(2, 1))
+	}
+	weighted := &theme{WeightClasses: map[string]string{"1": "calm"}}
+	if weighted.WeightClass(1) != "calm" || weighted.WeightClass(2) != "weight-2" {
+		t.Errorf("expected mapped and default weight classes, got %q and %q", weighted.WeightClass(1), weighted.WeightClass(2))
+	}
+	if custom.ServiceClass(3, 0) != "service-3-0" {
+		t.Errorf("expected default service class, got %q", custom.ServiceClass(3, 0))
+	}
+}
+
+func TestSelectTheme(t *testing.T) {
+	originalThemes := themes
+	originalDefaultTheme := defaultTheme
+	defer func() {
+		themes = originalThemes
+		defaultTheme = originalDefaultTheme
+	}()
+
+	themes = map[string]*theme{
+		"default": {Name: "default"},
+		"dark":    {Name: "dark"},
+	}
+	defaultTheme = "dark"
+
+	if selectTheme("default").Name != "default" {
+		t.Errorf("expected requested theme")
+	}
+	if selectTheme("unknown").Name != "dark" {
+		t.Errorf("expected configured default theme for unknown names")
+	}
+
+	themes = nil
+	if selectTheme("dark").Name != defaultThemeName {
+		t.Errorf("expected built in look without loaded themes")
+	}
+}
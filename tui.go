@@ -0,0 +1,655 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+// ANSI sequences of the terminal UI
+const (
+	ansiReverse     = "\033[7m"
+	ansiClearLine   = "\033[K"
+	ansiClearBelow  = "\033[J"
+	ansiCursorHome  = "\033[H"
+	ansiAltScreen   = "\033[?1049h"
+	ansiMainScreen  = "\033[?1049l"
+	ansiHideCursor  = "\033[?25l"
+	ansiShowCursor  = "\033[?25h"
+	tuiHelp         = "↑/↓ move  enter expand  a acknowledge  d downtime  o open  r refresh  q quit"
+	tuiHeaderLines  = 3
+	tuiFooterLines  = 2
+	defaultTermRows = 24
+	defaultTermCols = 80
+)
+
+type tuiOptions struct {
+	Query    url.Values
+	Author   string
+	Downtime time.Duration
+	Color    bool
+}
+
+// tuiRow is a line of the problem list. Detail rows list the hosts of an
+// expanded aggregated service row.
+type tuiRow struct {
+	// Identifies the row across refreshes, so the cursor stays on it
+	Key     string
+	State   int
+	IsHost  bool
+	Detail  bool
+	IsNew   bool
+	Label   string
+	Host    string
+	Service string
+	Since   string
+	// RowID of the aggregated record toggled by this row, empty if there is nothing to expand
+	Toggle   string
+	Expanded bool
+	Target   icinga2apiclient.ActionTarget
+	// Path and query of the row in Icinga Web, relative to ICINGA2_BASE_URL
+	Link string
+}
+
+type tuiPrompt struct {
+	Label string
+	Input string
+	// Icinga2 rejects actions without a comment
+	Required bool
+	Submit   func(input string)
+}
+
+// tui is the state of "icinga-dashboard tui", a full-screen terminal version
+// of the dashboard that talks to Icinga2 through the configured client.
+type tui struct {
+	options  tuiOptions
+	page     PageVariables
+	rows     []tuiRow
+	expanded map[string]bool
+	cursor   int
+	offset   int
+	status   string
+	prompt   *tuiPrompt
+	// Terminal height of the last render, for paging
+	height int
+	quit   bool
+}
+
+func newTUI(options tuiOptions) *tui {
+	return &tui{options: options, expanded: make(map[string]bool)}
+}
+
+// runTUI runs the terminal UI until the user quits. It returns the exit code.
+func runTUI(args []string, stdin *os.File, stdout *os.File, stderr io.Writer) int {
+	options, err := parseTUIFlags(args, stderr)
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+	options.Color = os.Getenv("NO_COLOR") == ""
+
+	restore, err := enableRawMode(stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error switching the terminal to raw mode: %v\n", err)
+		return 1
+	}
+	fmt.Fprint(stdout, ansiAltScreen+ansiHideCursor)
+	defer func() {
+		fmt.Fprint(stdout, ansiShowCursor+ansiMainScreen)
+		restore()
+	}()
+
+	// Errors are shown in the UI, log lines would scroll the screen
+	originalLog, originalClientLog := logOutput, icinga2apiclient.LogOutput
+	logOutput, icinga2apiclient.LogOutput = io.Discard, io.Discard
+	defer func() { logOutput, icinga2apiclient.LogOutput = originalLog, originalClientLog }()
+
+	keys := make(chan string)
+	go readKeys(stdin, keys)
+	ticker := time.NewTicker(refreshIntervalSeconds * time.Second)
+	defer ticker.Stop()
+
+	t := newTUI(options)
+	t.refresh()
+	for !t.quit {
+		rows, cols := terminalSize(stdin)
+		t.render(stdout, cols, rows)
+		select {
+		case key, ok := <-keys:
+			if !ok {
+				return 0
+			}
+			t.handleKey(key)
+		case <-ticker.C:
+			t.refresh()
+		}
+	}
+	return 0
+}
+
+func parseTUIFlags(args []string, stderr io.Writer) (tuiOptions, error) {
+	options := tuiOptions{Query: url.Values{}}
+
+	flags := flag.NewFlagSet("icinga-dashboard tui", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&options.Author, "author", os.Getenv("USER"), "Author of acknowledgements and downtimes")
+	flags.DurationVar(&options.Downtime, "downtime", 2*time.Hour, "Default duration of downtimes")
+	filters := make(map[string]*string)
+	for _, name := range cliFilters {
+		filters[name] = flags.String(name, "", "Same as the query parameter \""+name+"\" of the dashboard")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return options, err
+	}
+	for name, value := range filters {
+		if *value != "" {
+			options.Query.Set(name, *value)
+		}
+	}
+	return options, nil
+}
+
+// enableRawMode passes every key press to the UI without echoing it. stty
+// keeps this free of platform specific ioctls.
+func enableRawMode(tty *os.File) (func(), error) {
+	saved, err := stty(tty, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(tty, "raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(tty, strings.TrimSpace(saved)) }, nil
+}
+
+func terminalSize(tty *os.File) (int, int) {
+	output, err := stty(tty, "size")
+	if err != nil {
+		return defaultTermRows, defaultTermCols
+	}
+	var rows, cols int
+	if _, err := fmt.Sscan(output, &rows, &cols); err != nil || rows == 0 || cols == 0 {
+		return defaultTermRows, defaultTermCols
+	}
+	return rows, cols
+}
+
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	output, err := cmd.Output()
+	return string(output), err
+}
+
+// readKeys sends the keys read from r until it is closed.
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	buffer := make([]byte, 64)
+	for {
+		n, err := r.Read(buffer)
+		for _, key := range parseKeys(buffer[:n]) {
+			keys <- key
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+var escapeSequences = map[string]string{
+	"\033[A": "up", "\033OA": "up",
+	"\033[B": "down", "\033OB": "down",
+	"\033[5~": "pgup", "\033[6~": "pgdown",
+	"\033[H": "home", "\033[1~": "home",
+	"\033[F": "end", "\033[4~": "end",
+}
+
+// parseKeys splits the input of a raw terminal into keys. Escape sequences
+// become names like "up", unknown ones are dropped.
+func parseKeys(data []byte) []string {
+	var keys []string
+	for len(data) > 0 {
+		switch data[0] {
+		case '\033':
+			if len(data) == 1 {
+				return append(keys, "esc")
+			}
+			end := 2
+			for end < len(data) && (data[end] < 0x40 || data[end] > 0x7e) {
+				end++
+			}
+			end = min(end+1, len(data))
+			if name, ok := escapeSequences[string(data[:end])]; ok {
+				keys = append(keys, name)
+			}
+			data = data[end:]
+			continue
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		case 0x7f, '\b':
+			keys = append(keys, "backspace")
+		case 0x03:
+			keys = append(keys, "ctrl-c")
+		default:
+			r, size := utf8.DecodeRune(data)
+			keys = append(keys, string(r))
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+	return keys
+}
+
+// refresh reloads the problems with the same filters as the web UI.
+func (t *tui) refresh() {
+	var selected string
+	if t.cursor < len(t.rows) {
+		selected = t.rows[t.cursor].Key
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/?"+t.options.Query.Encode(), nil)
+	t.page = buildPageVariables(completeRequest(req), problems)
+	t.buildRows(selected)
+}
+
+func (t *tui) buildRows(selected string) {
+	t.rows = buildTUIRows(t.page, t.options.Query, t.expanded, now())
+	t.cursor = min(t.cursor, max(len(t.rows)-1, 0))
+	for i, row := range t.rows {
+		if row.Key == selected {
+			t.cursor = i
+		}
+	}
+}
+
+// buildTUIRows lists the hosts and services like index.html, filtered by the
+// "host" and "service" parameters like in the v2 API. Aggregated rows follow
+// the expand mode of the page until they are toggled in expanded.
+func buildTUIRows(page PageVariables, query url.Values, expanded map[string]bool, currentTime time.Time) []tuiRow {
+	var rows []tuiRow
+	for _, host := range page.HostRecords {
+		if !matchesName(query.Get("host"), host.Name) {
+			continue
+		}
+		label := strings.ToUpper(hostStateNumToString(host.State))
+		if host.UnreachableHostsCount > 0 {
+			label += fmt.Sprintf(" (%d unreachable)", host.UnreachableHostsCount)
+		}
+		rows = append(rows, tuiRow{
+			Key:     host.RowID(),
+			State:   2,
+			IsHost:  true,
+			IsNew:   host.IsNew,
+			Label:   label,
+			Host:    host.Name,
+			Service: "-",
+			Since:   tuiSince(host.LastStateChange, currentTime),
+			Target:  icinga2apiclient.ActionTarget{Hosts: []string{host.Name}},
+			Link:    "/host?name=" + host.URLEncodedHost(),
+		})
+	}
+
+	for _, record := range page.ServiceRecords {
+		if !matchesName(query.Get("host"), record.AggregatedHosts...) || !matchesName(query.Get("service"), record.AggregatedServices...) {
+			continue
+		}
+		label := strings.ToUpper(stateNumToString(record.State))
+		if record.StateType == 0 {
+			label += " (soft)"
+		}
+		row := tuiRow{
+			Key:     record.RowID(),
+			State:   record.State,
+			IsNew:   record.IsNew,
+			Label:   label,
+			Host:    record.HostField,
+			Service: record.Name,
+			Since:   tuiSince(record.Since, currentTime),
+			Target:  serviceTarget(record.Members),
+			Link:    "/service?name=" + record.URLEncodedService() + "&host.name=" + record.URLEncodedHost(),
+		}
+		if !record.IsAggregated {
+			rows = append(rows, row)
+			continue
+		}
+
+		row.Toggle = record.RowID()
+		row.Link = "/services?" + record.URLEncodedFilter()
+		isExpanded, toggled := expanded[record.RowID()]
+		if !toggled {
+			isExpanded = record.Expanded
+		}
+		row.Expanded = isExpanded
+		if isExpanded {
+			row.Label = "▾ " + row.Label
+		} else {
+			row.Label = "▸ " + row.Label
+		}
+		rows = append(rows, row)
+		if !isExpanded {
+			continue
+		}
+
+		for _, host := range record.AggregatedHosts {
+			members := record.HostMembers(host)
+			var services []string
+			for _, member := range members {
+				services = append(services, member.Service)
+			}
+			rows = append(rows, tuiRow{
+				Key:     record.RowID() + "|" + host,
+				State:   record.State,
+				Detail:  true,
+				Host:    "  " + host,
+				Service: strings.Join(services, ","),
+				Target:  serviceTarget(members),
+				Link:    "/services?" + buildFilter("name", services) + "&host.name=" + url.QueryEscape(host),
+			})
+		}
+	}
+	return rows
+}
+
+func tuiSince(since timestamp, currentTime time.Time) string {
+	if since.IsZero() {
+		return "-"
+	}
+	return formatDuration(currentTime.Sub(since.Time))
+}
+
+func (t *tui) handleKey(key string) {
+	if t.prompt != nil {
+		t.handlePromptKey(key)
+		return
+	}
+
+	t.status = ""
+	page := max(t.visibleRows(cmp.Or(t.height, defaultTermRows)), 1)
+	switch key {
+	case "q", "ctrl-c":
+		t.quit = true
+	case "up", "k":
+		t.cursor = max(t.cursor-1, 0)
+	case "down", "j":
+		t.cursor = min(t.cursor+1, max(len(t.rows)-1, 0))
+	case "pgup":
+		t.cursor = max(t.cursor-page, 0)
+	case "pgdown":
+		t.cursor = min(t.cursor+page, max(len(t.rows)-1, 0))
+	case "home", "g":
+		t.cursor = 0
+	case "end", "G":
+		t.cursor = max(len(t.rows)-1, 0)
+	case "r":
+		t.refresh()
+	case "enter", " ":
+		t.toggle()
+	case "a":
+		t.acknowledge()
+	case "d":
+		t.scheduleDowntime()
+	case "o":
+		t.open()
+	}
+}
+
+func (t *tui) handlePromptKey(key string) {
+	switch key {
+	case "esc", "ctrl-c":
+		t.prompt = nil
+		t.status = "Cancelled"
+	case "enter":
+		if t.prompt.Required && strings.TrimSpace(t.prompt.Input) == "" {
+			t.status = "A comment is required"
+			return
+		}
+		prompt := t.prompt
+		t.prompt = nil
+		prompt.Submit(prompt.Input)
+	case "backspace":
+		if input := []rune(t.prompt.Input); len(input) > 0 {
+			t.prompt.Input = string(input[:len(input)-1])
+		}
+	default:
+		if utf8.RuneCountInString(key) == 1 {
+			t.prompt.Input += key
+		}
+	}
+}
+
+func (t *tui) selectedRow() (tuiRow, bool) {
+	if t.cursor >= len(t.rows) {
+		return tuiRow{}, false
+	}
+	return t.rows[t.cursor], true
+}
+
+func (t *tui) toggle() {
+	row, ok := t.selectedRow()
+	if !ok || row.Toggle == "" {
+		return
+	}
+	t.expanded[row.Toggle] = !row.Expanded
+	t.buildRows(row.Key)
+}
+
+func (t *tui) acknowledge() {
+	row, ok := t.selectedRow()
+	if !ok {
+		return
+	}
+	t.prompt = &tuiPrompt{
+		Label:    "Acknowledge " + describeTarget(row.Target) + ", comment: ",
+		Required: true,
+		Submit: func(comment string) {
+			if err := client.AcknowledgeProblem(row.Target, t.options.Author, comment); err != nil {
+				t.status = "Error acknowledging: " + err.Error()
+				return
+			}
+			t.status = "Acknowledged " + describeTarget(row.Target)
+			t.refresh()
+		},
+	}
+}
+
+// scheduleDowntime asks for the duration, then for the comment.
+func (t *tui) scheduleDowntime() {
+	row, ok := t.selectedRow()
+	if !ok {
+		return
+	}
+	t.prompt = &tuiPrompt{
+		Label: "Downtime for " + describeTarget(row.Target) + ", duration: ",
+		Input: t.options.Downtime.String(),
+		Submit: func(input string) {
+			duration, err := time.ParseDuration(input)
+			if err != nil || duration <= 0 {
+				t.status = "Invalid duration: " + input
+				return
+			}
+			t.prompt = &tuiPrompt{
+				Label:    "Downtime for " + describeTarget(row.Target) + " (" + formatDuration(duration) + "), comment: ",
+				Required: true,
+				Submit: func(comment string) {
+					start := now()
+					if err := client.ScheduleDowntime(row.Target, t.options.Author, comment, start, start.Add(duration)); err != nil {
+						t.status = "Error scheduling downtime: " + err.Error()
+						return
+					}
+					t.status = "Scheduled downtime for " + describeTarget(row.Target)
+					t.refresh()
+				},
+			}
+		},
+	}
+}
+
+// open starts $BROWSER with the Icinga Web link of the row. Without a
+// browser, e.g. on a jump host, the link is shown to be clicked in the terminal.
+func (t *tui) open() {
+	row, ok := t.selectedRow()
+	if !ok {
+		return
+	}
+	if baseURL == "" {
+		t.status = "ICINGA2_BASE_URL is not set"
+		return
+	}
+	link := baseURL + row.Link
+	if browser := os.Getenv("BROWSER"); browser != "" {
+		if err := exec.Command(browser, link).Start(); err == nil {
+			t.status = "Opened " + link
+			return
+		}
+	}
+	// OSC 8 makes the link clickable in terminals supporting it
+	t.status = "\033]8;;" + link + "\033\\" + link + "\033]8;;\033\\"
+}
+
+// serviceTarget selects exactly the services of members.
+func serviceTarget(members []PageServiceMember) icinga2apiclient.ActionTarget {
+	var target icinga2apiclient.ActionTarget
+	for _, member := range members {
+		target.Services = append(target.Services, icinga2apiclient.ServiceName{Host: member.Host, Service: member.Service})
+	}
+	return target
+}
+
+func describeTarget(target icinga2apiclient.ActionTarget) string {
+	if len(target.Services) == 0 {
+		return cliList(target.Hosts, "hosts")
+	}
+	var hosts, services []string
+	for _, name := range target.Services {
+		if !slices.Contains(hosts, name.Host) {
+			hosts = append(hosts, name.Host)
+		}
+		if !slices.Contains(services, name.Service) {
+			services = append(services, name.Service)
+		}
+	}
+	return cliList(services, "services") + " on " + cliList(hosts, "hosts")
+}
+
+func (t *tui) visibleRows(height int) int {
+	return height - tuiHeaderLines - tuiFooterLines - len(t.banners())
+}
+
+// banners are the warnings shown above the problem list.
+func (t *tui) banners() []string {
+	var banners []string
+	if t.page.Error != nil {
+		banners = append(banners, "Error talking to Icinga2: "+t.page.Error.Error())
+	}
+	// Same messages as the banners of index.html
+	for _, feature := range t.page.DisabledFeatures {
+		banners = append(banners, t.page.T("feature_disabled", t.page.T("feature."+feature)))
+	}
+	if t.page.Icinga != nil && t.page.Icinga.RecentlyRestarted {
+		banners = append(banners, t.page.T("restarted", t.page.Icinga.UptimeString()))
+	}
+	return banners
+}
+
+// render draws the whole screen, every line is cut to width.
+func (t *tui) render(w io.Writer, width int, height int) {
+	t.height = height
+	var lines []string
+	line := func(text string, color string) {
+		lines = append(lines, colorize(truncate(text, width), color, t.options.Color && color != ""))
+	}
+
+	line(t.summary(), ansiBold)
+	for _, banner := range t.banners() {
+		line(banner, ansiRed)
+	}
+	line("", "")
+
+	var table bytes.Buffer
+	writer := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "  STATE\tHOST\tSERVICE\tSINCE")
+	for _, row := range t.rows {
+		label := row.Label
+		if row.IsNew {
+			label += " NEW"
+		}
+		fmt.Fprintf(writer, "  %s\t%s\t%s\t%s\n", label, row.Host, row.Service, row.Since)
+	}
+	writer.Flush()
+	tableLines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	line(tableLines[0], ansiBold)
+
+	visible := max(t.visibleRows(height), 1)
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+visible {
+		t.offset = t.cursor - visible + 1
+	}
+	t.offset = min(t.offset, max(len(t.rows)-visible, 0))
+	if len(t.rows) == 0 {
+		line("  No problems", ansiBold)
+	}
+	for i := t.offset; i < min(t.offset+visible, len(t.rows)); i++ {
+		text := tableLines[i+1]
+		color := ansiRed
+		if !t.rows[i].IsHost {
+			color = stateColor(t.rows[i].State)
+		}
+		if i == t.cursor {
+			text = ">" + text[1:]
+			color += ansiReverse
+		}
+		line(text, color)
+	}
+
+	for len(lines) < height-tuiFooterLines {
+		line("", "")
+	}
+	if t.prompt != nil {
+		lines = append(lines, truncate(t.prompt.Label+t.prompt.Input+"_", width))
+	} else {
+		// Not cut, the status may carry the escape sequences of a link
+		lines = append(lines, t.status)
+	}
+	line(tuiHelp, "")
+
+	// Raw mode doesn't turn \n into \r\n
+	fmt.Fprint(w, ansiCursorHome+strings.Join(lines, ansiClearLine+"\r\n")+ansiClearLine+ansiClearBelow)
+}
+
+func (t *tui) summary() string {
+	summary := "Icinga Dashboard  " + t.page.TimeString
+	if status := t.page.CIBStatus; status != nil {
+		summary += fmt.Sprintf("   Hosts: %d up, %d down   Services: %d OK, %d warning, %d critical, %d unknown",
+			status.NumHostsUp, status.NumHostsDown, status.NumServicesOk, status.NumServicesWarning, status.NumServicesCritical, status.NumServicesUnknown)
+	}
+	if t.page.StaleCount > 0 {
+		summary += fmt.Sprintf("   Stale: %d", t.page.StaleCount)
+	}
+	return summary
+}
+
+func truncate(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	return string([]rune(text)[:max(width, 0)])
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"q", []string{"q"}},
+		{"\033[A\033[B", []string{"up", "down"}},
+		{"\033OA\033[6~", []string{"up", "pgdown"}},
+		{"\033", []string{"esc"}},
+		{"\033[Z", nil},
+		{"ab\r\x7f", []string{"a", "b", "enter", "backspace"}},
+		{"\x03", []string{"ctrl-c"}},
+		{"ü", []string{"ü"}},
+	}
+	for _, test := range tests {
+		if keys := parseKeys([]byte(test.input)); !reflect.DeepEqual(keys, test.expected) {
+			t.Errorf("parseKeys(%q) = %q, want %q", test.input, keys, test.expected)
+		}
+	}
+}
+
+func newTestTUI(t *testing.T, stub stubDashboardClient) *tui {
+	withAPITestClient(t, stub)
+	originalBaseURL := baseURL
+	t.Cleanup(func() { baseURL = originalBaseURL })
+	baseURL = "https://icinga.example.com/icingaweb2/monitoring"
+	t.Setenv("BROWSER", "")
+
+	ui := newTUI(tuiOptions{Query: url.Values{}, Author: "jdoe", Downtime: 2 * time.Hour})
+	ui.refresh()
+	return ui
+}
+
+func renderTestTUI(ui *tui) string {
+	var screen bytes.Buffer
+	ui.render(&screen, 100, 14)
+	return screen.String()
+}
+
+func TestTUIRows(t *testing.T) {
+	ui := newTestTUI(t, apiTestClient())
+
+	var lines []string
+	for _, row := range ui.rows {
+		lines = append(lines, row.Label+"|"+row.Host+"|"+row.Service+"|"+row.Since)
+	}
+	expected := []string{
+		"DOWN (1 unreachable)|core-sw-1|-|1h 9m",
+		"▸ CRITICAL|2 Hosts|http|1h 9m",
+		"WARNING (soft)|db-1|disk|-",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("unexpected rows\n got: %q\nwant: %q", lines, expected)
+	}
+
+	ui.handleKey("down")
+	ui.handleKey("enter")
+	if len(ui.rows) != 5 || !ui.rows[2].Detail || ui.rows[3].Host != "  web-2" || ui.cursor != 1 {
+		t.Fatalf("expected the hosts of the aggregated row below it, got %+v", ui.rows)
+	}
+	if !strings.HasPrefix(ui.rows[1].Label, "▾") {
+		t.Errorf("expected the row to be marked expanded, got %s", ui.rows[1].Label)
+	}
+
+	// Expanded rows stay expanded across refreshes
+	ui.refresh()
+	if len(ui.rows) != 5 || ui.cursor != 1 {
+		t.Errorf("expected the row to stay expanded and selected, got %d rows, cursor %d", len(ui.rows), ui.cursor)
+	}
+	ui.handleKey(" ")
+	if len(ui.rows) != 3 {
+		t.Errorf("expected the row to collapse, got %d rows", len(ui.rows))
+	}
+}
+
+func TestTUIFiltersByName(t *testing.T) {
+	stub := apiTestClient()
+	withAPITestClient(t, stub)
+	options, err := parseTUIFlags([]string{"-host", "web-2", "-service", "http"}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := newTUI(options)
+	ui.refresh()
+
+	if len(ui.rows) != 1 || ui.rows[0].Service != "http" {
+		t.Errorf("expected only the http row, got %+v", ui.rows)
+	}
+
+	ui.options.Query.Set("service", "disk")
+	ui.buildRows("")
+	if len(ui.rows) != 0 {
+		t.Errorf("expected no rows for disk on web-2, got %+v", ui.rows)
+	}
+}
+
+func TestTUIBanners(t *testing.T) {
+	stub := apiTestClient()
+	stub.appStatus = &icinga2apiclient.IcingaApplication{EnableHostChecks: true, EnableServiceChecks: true, EnableEventHandlers: true, EnableFlapping: true, EnablePerfdata: true}
+	ui := newTestTUI(t, stub)
+
+	banners := ui.banners()
+	if len(banners) != 1 || banners[0] != "Notifications disabled globally!" || slices.Contains(banners[1:], banners[0]) {
+		t.Errorf("expected one banner per disabled feature, got %q", banners)
+	}
+
+	ui.options.Query.Set("lang", "de")
+	ui.refresh()
+	if banners := ui.banners(); len(banners) != 1 || banners[0] != "Benachrichtigungen global deaktiviert!" {
+		t.Errorf("expected translated banners, got %q", banners)
+	}
+}
+
+func TestTUIRender(t *testing.T) {
+	ui := newTestTUI(t, apiTestClient())
+	ui.handleKey("j")
+
+	screen := renderTestTUI(ui)
+	lines := strings.Split(screen, ansiClearLine+"\r\n")
+	if len(lines) != 14 {
+		t.Fatalf("expected the screen to be filled, got %d lines", len(lines))
+	}
+	for _, expected := range []string{
+		"Hosts: 9 up, 1 down   Services: 40 OK, 0 warning, 3 critical, 0 unknown",
+		"Host checks disabled globally!",
+		"  STATE",
+		"  DOWN (1 unreachable)  core-sw-1  -        1h 9m",
+		"> ▸ CRITICAL            2 Hosts    http     1h 9m",
+		tuiHelp,
+	} {
+		if !strings.Contains(screen, expected) {
+			t.Errorf("expected %q on the screen, got\n%s", expected, screen)
+		}
+	}
+	if strings.Contains(screen, ansiRed) {
+		t.Errorf("expected no colors, got %q", screen)
+	}
+
+	ui.options.Color = true
+	if screen := renderTestTUI(ui); !strings.Contains(screen, ansiRed+ansiReverse+"> ▸ CRITICAL") {
+		t.Errorf("expected the selected row in reverse video, got %q", screen)
+	}
+}
+
+func TestTUIScrolling(t *testing.T) {
+	stub := apiTestClient()
+	stub.services = nil
+	for i := 0; i < 20; i++ {
+		stub.services = append(stub.services, icinga2apiclient.Service{HostName: "web", ServiceName: "disk-" + string(rune('a'+i)), State: 1, StateType: 1})
+	}
+	ui := newTestTUI(t, stub)
+	ui.handleKey("G")
+
+	screen := renderTestTUI(ui)
+	if !strings.Contains(screen, "> WARNING               web        disk-t") || strings.Contains(screen, "core-sw-1") {
+		t.Errorf("expected the list to scroll to the last row, got\n%s", screen)
+	}
+	ui.handleKey("g")
+	if screen := renderTestTUI(ui); !strings.Contains(screen, "> DOWN") {
+		t.Errorf("expected the list to scroll back to the first row, got\n%s", screen)
+	}
+}
+
+func TestTUIAcknowledge(t *testing.T) {
+	var actions []stubAction
+	stub := apiTestClient()
+	stub.actions = &actions
+	ui := newTestTUI(t, stub)
+
+	ui.handleKey("j")
+	ui.handleKey("a")
+	if screen := renderTestTUI(ui); !strings.Contains(screen, "Acknowledge http on web-1,web-2, comment: _") {
+		t.Errorf("expected the comment prompt, got\n%s", screen)
+	}
+	for _, key := range []string{"o", "n", "x", "backspace", " ", "i", "t", "enter"} {
+		ui.handleKey(key)
+	}
+
+	expected := []stubAction{{
+		Name:    "acknowledge",
+		Target:  icinga2apiclient.ActionTarget{Services: []icinga2apiclient.ServiceName{{Host: "web-1", Service: "http"}, {Host: "web-2", Service: "http"}}},
+		Comment: "on it",
+	}}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("unexpected actions: got %+v, want %+v", actions, expected)
+	}
+	if ui.status != "Acknowledged http on web-1,web-2" {
+		t.Errorf("unexpected status %q", ui.status)
+	}
+
+	ui.handleKey("a")
+	ui.handleKey("esc")
+	if len(actions) != 1 || ui.status != "Cancelled" {
+		t.Errorf("expected the prompt to be cancelled, got %+v and %q", actions, ui.status)
+	}
+}
+
+func TestTUIScheduleDowntime(t *testing.T) {
+	var actions []stubAction
+	stub := apiTestClient()
+	stub.actions = &actions
+	ui := newTestTUI(t, stub)
+
+	ui.handleKey("d")
+	if ui.prompt == nil || ui.prompt.Input != "2h0m0s" {
+		t.Fatalf("expected the default duration in the prompt, got %+v", ui.prompt)
+	}
+	ui.prompt.Input = "30m"
+	ui.handleKey("enter")
+	for _, key := range []string{"s", "w", "enter"} {
+		ui.handleKey(key)
+	}
+
+	start := now()
+	expected := []stubAction{{
+		Name:    "downtime",
+		Target:  icinga2apiclient.ActionTarget{Hosts: []string{"core-sw-1"}},
+		Comment: "sw",
+		Start:   start,
+		End:     start.Add(30 * time.Minute),
+	}}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("unexpected actions: got %+v, want %+v", actions, expected)
+	}
+
+	ui.handleKey("d")
+	ui.prompt.Input = "soon"
+	ui.handleKey("enter")
+	if len(actions) != 1 || ui.prompt != nil || ui.status != "Invalid duration: soon" {
+		t.Errorf("expected an invalid duration to be rejected, got %q", ui.status)
+	}
+}
+
+func TestTUIActionError(t *testing.T) {
+	stub := apiTestClient()
+	stub.actionErr = errors.New("HTTP 403: 403 Forbidden")
+	ui := newTestTUI(t, stub)
+
+	ui.handleKey("a")
+	ui.handleKey("x")
+	ui.handleKey("enter")
+	if ui.status != "Error acknowledging: HTTP 403: 403 Forbidden" {
+		t.Errorf("expected the error in the status line, got %q", ui.status)
+	}
+}
+
+func TestTUIRequiresComment(t *testing.T) {
+	var actions []stubAction
+	stub := apiTestClient()
+	stub.actions = &actions
+	ui := newTestTUI(t, stub)
+
+	ui.handleKey("a")
+	ui.handleKey(" ")
+	ui.handleKey("enter")
+	if len(actions) != 0 || ui.prompt == nil || ui.status != "A comment is required" {
+		t.Fatalf("expected the prompt to stay open without a comment, got %+v and status %q", actions, ui.status)
+	}
+	ui.handleKey("x")
+	ui.handleKey("enter")
+	if len(actions) != 1 || actions[0].Comment != " x" {
+		t.Errorf("expected an acknowledgement once commented, got %+v", actions)
+	}
+}
+
+func TestTUIOpen(t *testing.T) {
+	ui := newTestTUI(t, apiTestClient())
+
+	ui.handleKey("o")
+	link := "https://icinga.example.com/icingaweb2/monitoring/host?name=core-sw-1"
+	if !strings.Contains(ui.status, "\033]8;;"+link+"\033\\") {
+		t.Errorf("expected a link to the host, got %q", ui.status)
+	}
+
+	ui.handleKey("j")
+	ui.handleKey("enter")
+	ui.handleKey("j")
+	ui.handleKey("o")
+	if !strings.Contains(ui.status, "/services?name=http&host.name=web-1") {
+		t.Errorf("expected a link to the service on the host, got %q", ui.status)
+	}
+
+	baseURL = ""
+	ui.handleKey("o")
+	if ui.status != "ICINGA2_BASE_URL is not set" {
+		t.Errorf("unexpected status %q", ui.status)
+	}
+}
+
+func TestTUIActionsOnMixedGroup(t *testing.T) {
+	var actions []stubAction
+	stub := apiTestClient()
+	stub.actions = &actions
+	stub.hosts = nil
+	stub.services = []icinga2apiclient.Service{
+		{HostName: "db-1", ServiceName: "disk", State: 2, StateType: 1, Vars: map[string]interface{}{"team": "storage"}},
+		{HostName: "db-2", ServiceName: "raid", State: 2, StateType: 1, Vars: map[string]interface{}{"team": "storage"}},
+	}
+	withAPITestClient(t, stub)
+	ui := newTUI(tuiOptions{Query: url.Values{"groupBy": {"vars.team"}}, Author: "jdoe"})
+	ui.refresh()
+
+	ui.handleKey("a")
+	ui.handleKey("x")
+	ui.handleKey("enter")
+	ui.handleKey("enter")
+	ui.handleKey("j")
+	ui.handleKey("j")
+	ui.handleKey("a")
+	ui.handleKey("y")
+	ui.handleKey("enter")
+
+	expected := []stubAction{
+		{Name: "acknowledge", Comment: "x", Target: icinga2apiclient.ActionTarget{Services: []icinga2apiclient.ServiceName{{Host: "db-1", Service: "disk"}, {Host: "db-2", Service: "raid"}}}},
+		{Name: "acknowledge", Comment: "y", Target: icinga2apiclient.ActionTarget{Services: []icinga2apiclient.ServiceName{{Host: "db-2", Service: "raid"}}}},
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected only the services of the row, got %+v", actions)
+	}
+	if ui.rows[2].Host != "  db-2" || ui.rows[2].Service != "raid" {
+		t.Errorf("expected db-2 to list only its own service, got %+v", ui.rows[2])
+	}
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
@@ -13,35 +17,146 @@ type PageVariables struct {
 	Time                  timestamp                   `json:"timestamp"`
 	ServiceRecords        []PageServiceListRecord     `json:"services"`
 	HostRecords           []PageHostListRecord        `json:"hosts"`
+	ClusterRecords        []PageClusterRecord         `json:"cluster"`
 	CIBStatus             *icinga2apiclient.CIBStatus `json:"cib_status"`
 	MinStateType          string                      `json:"min_state_type"`
 	MinState              string                      `json:"min_state"`
 	MaxState              string                      `json:"max_state"`
+	GroupBy               string                      `json:"group_by"`
+	SortOrder             string                      `json:"sort_order"`
 	Error                 error                       `json:"error"`
 	BaseURL               string                      `json:"base_url"`
 	NotificationsDisabled bool                        `json:"notifications_disabled"`
+	DisabledFeatures      []string                    `json:"disabled_features"`
+	RefreshInterval       int                         `json:"refresh_interval"`
+	Theme                 *theme                      `json:"-"`
+	KioskMode             string                      `json:"kiosk_mode"`
+	Page                  int                         `json:"page"`
+	Pages                 int                         `json:"pages"`
+	Overflow              string                      `json:"overflow"`
+	View                  string                      `json:"view"`
+	Playlist              string                      `json:"playlist"`
+	Pinned                bool                        `json:"pinned"`
+	NewProblems           int                         `json:"new_problems"`
+	AlertSound            bool                        `json:"alert_sound"`
+	AlertSoundURL         string                      `json:"alert_sound_url"`
+	AlertFlash            bool                        `json:"alert_flash"`
+	Language              string                      `json:"language"`
+	Messages              localizer                   `json:"-"`
+	TimeRFC3339           string                      `json:"time"`
+	Timezone              string                      `json:"timezone"`
+	DateString            string                      `json:"date_string"`
+	Clocks                []clock                     `json:"clocks"`
+	StaleRecords          []PageStaleRecord           `json:"stale"`
+	StaleCount            int                         `json:"stale_count"`
+	IcingaVersion         string                      `json:"icinga_version"`
+	ProgramStart          timestamp                   `json:"program_start"`
+	Icinga                *icingaInfo                 `json:"icinga"`
+	ShowFooter            bool                        `json:"-"`
+	Widgets               []widget                    `json:"widgets"`
+}
+
+// T translates a message into the language of the page, see localizer.T.
+func (p PageVariables) T(id string, args ...interface{}) string {
+	return p.Messages.T(id, args...)
+}
+
+// Percent formats a percentage with one decimal place, see icinga2apiclient.RoundPercent.
+func (p PageVariables) Percent(value float32) string {
+	return fmt.Sprintf("%.1f%%", icinga2apiclient.RoundPercent(value))
 }
 
 type PageServiceListRecord struct {
-	Name                 string   `json:"name"`
-	HostField            string   `json:"host_field"`
-	State                int      `json:"state"`
-	StateType            int      `json:"state_type"`
-	IsAggregated         bool     `json:"is_aggregated"`
-	AggregatedHosts      []string `json:"aggregated_hosts"`
-	AggregatedHostsCount int      `json:"aggregated_hosts_count"`
+	Name                    string   `json:"name"`
+	HostField               string   `json:"host_field"`
+	GroupName               string   `json:"group_name"`
+	State                   int      `json:"state"`
+	StateType               int      `json:"state_type"`
+	IsAggregated            bool     `json:"is_aggregated"`
+	AggregatedHosts         []string `json:"aggregated_hosts"`
+	AggregatedHostsCount    int      `json:"aggregated_hosts_count"`
+	AggregatedServices      []string `json:"aggregated_services"`
+	AggregatedServicesCount int      `json:"aggregated_services_count"`
+	Expanded                bool     `json:"expanded"`
+	// The services represented by this record. Grouping by host, host group or
+	// custom var mixes hosts and services, so not every host has every service.
+	Members []PageServiceMember `json:"members"`
+	// Earliest and latest state change of the services within this record
+	Since           timestamp `json:"since"`
+	LastStateChange timestamp `json:"last_state_change"`
+	Priority        string    `json:"priority"`
+	// Severity computed from state and custom vars, see SEVERITY_WEIGHTS
+	Weight      int  `json:"weight"`
+	WeightLevel int  `json:"weight_level"`
+	IsNew       bool `json:"is_new"`
+}
+
+// PageServiceMember is one service of a PageServiceListRecord.
+type PageServiceMember struct {
+	Host            string    `json:"host"`
+	Service         string    `json:"service"`
+	LastStateChange timestamp `json:"last_state_change"`
+}
+
+// HostMembers returns the members of the record on host.
+func (r *PageServiceListRecord) HostMembers(host string) []PageServiceMember {
+	var members []PageServiceMember
+	for _, member := range r.Members {
+		if member.Host == host {
+			members = append(members, member)
+		}
+	}
+	return members
 }
 
 type PageHostListRecord struct {
-	State     int    `json:"state"`
-	StateType int    `json:"state_type"`
-	Name      string `json:"name"`
+	State                 int      `json:"state"`
+	StateType             int      `json:"state_type"`
+	Name                  string   `json:"name"`
+	UnreachableHosts      []string `json:"unreachable_hosts"`
+	UnreachableHostsCount int      `json:"unreachable_hosts_count"`
+	// The unreachable hosts with their own state, in the order of UnreachableHosts
+	UnreachableMembers []PageUnreachableHost `json:"unreachable_members"`
+	LastStateChange    timestamp             `json:"last_state_change"`
+	Priority           string                `json:"priority"`
+	IsNew              bool                  `json:"is_new"`
+}
+
+// PageUnreachableHost is a host collapsed under the failed parent it depends on.
+type PageUnreachableHost struct {
+	Name            string    `json:"name"`
+	StateType       int       `json:"state_type"`
+	LastStateChange timestamp `json:"last_state_change"`
+}
+
+// MarshalJSON adds RFC3339 variants of the unix timestamps.
+func (r PageServiceListRecord) MarshalJSON() ([]byte, error) {
+	type record PageServiceListRecord
+	return json.Marshal(struct {
+		record
+		SinceRFC3339           string `json:"since_rfc3339"`
+		LastStateChangeRFC3339 string `json:"last_state_change_rfc3339"`
+	}{record(r), rfc3339(r.Since), rfc3339(r.LastStateChange)})
+}
+
+// MarshalJSON adds RFC3339 variants of the unix timestamps.
+func (r PageHostListRecord) MarshalJSON() ([]byte, error) {
+	type record PageHostListRecord
+	return json.Marshal(struct {
+		record
+		LastStateChangeRFC3339 string `json:"last_state_change_rfc3339"`
+	}{record(r), rfc3339(r.LastStateChange)})
 }
 
 func (r *PageHostListRecord) URLEncodedHost() string {
 	return url.QueryEscape(r.Name)
 }
 
+// RowID identifies a record across page reloads, so alerts are raised only once per screen.
+func (r *PageHostListRecord) RowID() string {
+	return fmt.Sprintf("host-%x", sha1.Sum([]byte(r.Name)))
+}
+
 func (r *PageServiceListRecord) URLEncodedHost() string {
 	return url.QueryEscape(r.HostField)
 }
@@ -50,6 +165,43 @@ func (r *PageServiceListRecord) URLEncodedService() string {
 	return url.QueryEscape(r.Name)
 }
 
+// RowID identifies a record across page reloads, so expanded rows stay expanded.
+// The host field of aggregated records is a count like "3 Hosts", so it is left
+// out to keep the ID when hosts join or leave the group.
+func (r *PageServiceListRecord) RowID() string {
+	host := r.HostField
+	if r.IsAggregated {
+		host = ""
+	}
+	return fmt.Sprintf("row-%x", sha1.Sum([]byte(fmt.Sprintf("%s|%s|%s|%d|%d", r.GroupName, host, r.Name, r.State, r.StateType))))
+}
+
+// URLEncodedFilter returns an Icinga Web filter that matches exactly the
+// services represented by this record. Hosts and services are combined only
+// if every host has every service, otherwise the pairs are listed.
+func (r *PageServiceListRecord) URLEncodedFilter() string {
+	if len(r.Members) == 0 || len(r.AggregatedHosts)*len(r.AggregatedServices) == len(r.Members) {
+		return buildFilter("name", r.AggregatedServices) + "&" + buildFilter("host.name", r.AggregatedHosts)
+	}
+
+	var conditions []string
+	for _, member := range r.Members {
+		conditions = append(conditions, "(host.name="+url.QueryEscape(member.Host)+"&name="+url.QueryEscape(member.Service)+")")
+	}
+	return strings.Join(conditions, "|")
+}
+
+func buildFilter(column string, values []string) string {
+	var conditions []string
+	for _, value := range values {
+		conditions = append(conditions, column+"="+url.QueryEscape(value))
+	}
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return "(" + strings.Join(conditions, "|") + ")"
+}
+
 // Allows sorting services by state
 type ByState []PageServiceListRecord
 
@@ -81,11 +233,27 @@ type timestamp struct {
 	time.Time
 }
 
+// rfc3339 formats t in UTC, unknown times are left empty.
+func rfc3339(t timestamp) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// MarshalJSON writes a unix timestamp, unknown times are null.
 func (t timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
 	return []byte(strconv.FormatInt(time.Time(t.Time).Unix(), 10)), nil
 }
 
 func (t *timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*t = timestamp{}
+		return nil
+	}
 	i, err := strconv.ParseInt(string(data[:]), 10, 64)
 	if err != nil {
 		return err
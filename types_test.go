@@ -4,6 +4,7 @@ import (
 	"net/url"
 	"sort"
 	"testing"
+	"time"
 )
 
 func TestPageHostListRecord_URLEncodedHost(t *testing.T) {
@@ -30,6 +31,51 @@ func TestPageServiceListRecord_URLEncodedService(t *testing.T) {
 	}
 }
 
+func TestPageServiceListRecord_URLEncodedFilter(t *testing.T) {
+	r := PageServiceListRecord{
+		AggregatedServices: []string{"disk space"},
+		AggregatedHosts:    []string{"host-a", "host-b"},
+	}
+	expected := "name=disk+space&(host.name=host-a|host.name=host-b)"
+	if r.URLEncodedFilter() != expected {
+		t.Errorf("URLEncodedFilter = %v, want %v", r.URLEncodedFilter(), expected)
+	}
+}
+
+func TestPageServiceListRecord_URLEncodedFilterOfPairs(t *testing.T) {
+	r := PageServiceListRecord{
+		AggregatedServices: []string{"disk", "raid"},
+		AggregatedHosts:    []string{"db-1", "db-2"},
+		Members:            []PageServiceMember{{Host: "db-1", Service: "disk"}, {Host: "db-2", Service: "raid"}},
+	}
+	expected := "(host.name=db-1&name=disk)|(host.name=db-2&name=raid)"
+	if r.URLEncodedFilter() != expected {
+		t.Errorf("URLEncodedFilter = %v, want %v", r.URLEncodedFilter(), expected)
+	}
+}
+
+func TestPageServiceListRecord_RowID(t *testing.T) {
+	r := PageServiceListRecord{Name: "disk", HostField: "2 Hosts", State: 2, StateType: 1}
+	other := PageServiceListRecord{Name: "disk", HostField: "2 Hosts", State: 1, StateType: 1}
+	if r.RowID() != r.RowID() {
+		t.Errorf("RowID is not stable")
+	}
+	if r.RowID() == other.RowID() {
+		t.Errorf("RowID should differ for different states")
+	}
+
+	group := PageServiceListRecord{Name: "disk", HostField: "2 Hosts", State: 2, StateType: 1, IsAggregated: true}
+	grown := group
+	grown.HostField = "3 Hosts"
+	if group.RowID() != grown.RowID() {
+		t.Errorf("RowID of an aggregated record should not change with its hosts")
+	}
+	single := PageServiceListRecord{Name: "disk", HostField: "web-1", State: 2, StateType: 1}
+	if single.RowID() == (&PageServiceListRecord{Name: "disk", HostField: "web-2", State: 2, StateType: 1}).RowID() {
+		t.Errorf("RowID should differ for different hosts")
+	}
+}
+
 func TestByState_Sort(t *testing.T) {
 	list := ByState{
 		{Name: "A", State: 2, StateType: 1},
@@ -62,14 +108,41 @@ func TestByName_Sort(t *testing.T) {
 }
 
 func TestTimestamp_MarshalUnmarshalJSON(t *testing.T) {
-	ts := timestamp{}
-	data, err := ts.MarshalJSON()
-	if err != nil {
-		t.Errorf("MarshalJSON error: %v", err)
-	}
-	var ts2 timestamp
-	err = ts2.UnmarshalJSON(data)
-	if err != nil {
-		t.Errorf("UnmarshalJSON error: %v", err)
+	for _, ts := range []timestamp{{}, {time.Unix(1772368200, 0)}} {
+		data, err := ts.MarshalJSON()
+		if err != nil {
+			t.Errorf("MarshalJSON error: %v", err)
+		}
+		ts2 := timestamp{time.Now()}
+		err = ts2.UnmarshalJSON(data)
+		if err != nil {
+			t.Errorf("UnmarshalJSON error: %v", err)
+		}
+		if !ts2.Equal(ts.Time) {
+			t.Errorf("expected %v after a round trip of %s, got %v", ts, data, ts2)
+		}
+	}
+
+	data, _ := timestamp{}.MarshalJSON()
+	if string(data) != "null" {
+		t.Errorf("expected null for an unknown time, got %s", data)
+	}
+}
+
+func TestPageVariables_Percent(t *testing.T) {
+	tests := []struct {
+		value    float32
+		expected string
+	}{
+		{0, "0.0%"},
+		{100, "100.0%"},
+		{66.66667, "66.7%"},
+		{99.98, "99.9%"},
+		{0.01, "0.1%"},
+	}
+	for _, tt := range tests {
+		if got := (PageVariables{}).Percent(tt.value); got != tt.expected {
+			t.Errorf("Percent(%v) = %s, want %s", tt.value, got, tt.expected)
+		}
 	}
 }
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+// viewConfig is read from the file configured in VIEWS_FILE.
+//
+//	{
+//	  "views": {
+//	    "all-criticals": {"query": "minState=2&maxState=2"},
+//	    "network": {"query": "hostGroup=network&groupBy=host"}
+//	  },
+//	  "playlists": {
+//	    "ops": {
+//	      "views": [{"view": "all-criticals", "seconds": 30}, {"view": "network", "seconds": 15}],
+//	      "pin_on_critical_host": true
+//	    }
+//	  }
+//	}
+type viewConfig struct {
+	Views     map[string]view     `json:"views"`
+	Playlists map[string]playlist `json:"playlists"`
+}
+
+// view is a named set of dashboard query parameters.
+type view struct {
+	Query string `json:"query"`
+}
+
+// playlist cycles through a sequence of views on kiosk screens.
+type playlist struct {
+	Views []playlistEntry `json:"views"`
+	// Stay on PinView as long as it shows a host that is down in a hard state
+	PinOnCriticalHost bool `json:"pin_on_critical_host"`
+	// View to pin, defaults to the first view of the playlist
+	PinView string `json:"pin_view"`
+}
+
+type playlistEntry struct {
+	View    string `json:"view"`
+	Seconds int    `json:"seconds"`
+}
+
+func loadViewConfig(path string) (viewConfig, error) {
+	var config viewConfig
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	for name, v := range config.Views {
+		if _, err := url.ParseQuery(v.Query); err != nil {
+			return config, fmt.Errorf("view %s has an invalid query: %w", name, err)
+		}
+	}
+	for name, p := range config.Playlists {
+		if len(p.Views) == 0 {
+			return config, fmt.Errorf("playlist %s has no views", name)
+		}
+		for _, entry := range p.Views {
+			if _, ok := config.Views[entry.View]; !ok {
+				return config, fmt.Errorf("playlist %s references unknown view %s", name, entry.View)
+			}
+			if entry.Seconds <= 0 {
+				return config, fmt.Errorf("playlist %s needs a positive duration for view %s", name, entry.View)
+			}
+		}
+		if _, ok := config.Views[p.PinView]; p.PinView != "" && !ok {
+			return config, fmt.Errorf("playlist %s pins unknown view %s", name, p.PinView)
+		}
+	}
+
+	return config, nil
+}
+
+// withView returns the query parameters of the view selected by "view",
+// overwritten by all parameters given explicitly.
+func withView(query url.Values) url.Values {
+	v, ok := views.Views[query.Get("view")]
+	if !ok {
+		return query
+	}
+
+	merged, _ := url.ParseQuery(v.Query)
+	for key, values := range query {
+		merged[key] = values
+	}
+	return merged
+}
+
+// scheduledView returns the playlist entry due at the given unix time and
+// the number of seconds until the next entry is due.
+func (p playlist) scheduledView(unix int64) (playlistEntry, int) {
+	total := 0
+	for _, entry := range p.Views {
+		total += entry.Seconds
+	}
+
+	offset := int(unix % int64(total))
+	for _, entry := range p.Views {
+		if offset < entry.Seconds {
+			return entry, entry.Seconds - offset
+		}
+		offset -= entry.Seconds
+	}
+
+	return p.Views[0], p.Views[0].Seconds
+}
+
+func (p playlist) pinView() string {
+	if p.PinView != "" {
+		return p.PinView
+	}
+	return p.Views[0].View
+}
+
+// viewHasCriticalHost checks the pin condition of a playlist with the hosts
+// only, the full snapshot is built just for the view that is shown.
+func viewHasCriticalHost(r *http.Request, viewName string) bool {
+	query := withView(viewRequest(r, viewName).URL.Query())
+	minStateType := defaultMinStateType
+	if value, err := strconv.Atoi(query.Get("minStateType")); err == nil {
+		minStateType = value
+	}
+
+	hosts, err := client.GetHosts(minStateType)
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error getting hosts: %v\n", err)
+		return false
+	}
+	dependencies, err := client.GetDependencies()
+	if err != nil {
+		fmt.Fprintf(logOutput, "Error getting dependencies: %v\n", err)
+	}
+	if hostGroup := query.Get("hostGroup"); hostGroup != "" {
+		hosts = slices.DeleteFunc(hosts, func(host icinga2apiclient.Host) bool {
+			return !slices.Contains(host.Groups, hostGroup)
+		})
+	}
+	return hasCriticalHost(buildHostListRecords(hosts, dependencies))
+}
+
+func hasCriticalHost(hosts []PageHostListRecord) bool {
+	return slices.ContainsFunc(hosts, func(host PageHostListRecord) bool {
+		return host.State == 1 && host.StateType == 1
+	})
+}
+
+// viewRequest returns a copy of r showing the given view. All query
+// parameters of r except "playlist" are kept, e.g. to choose a theme per screen.
+func viewRequest(r *http.Request, viewName string) *http.Request {
+	query := r.URL.Query()
+	query.Del("playlist")
+	query.Set("view", viewName)
+
+	viewReq := r.Clone(r.Context())
+	viewReq.URL.RawQuery = query.Encode()
+	return viewReq
+}
+
+func renderKiosk(w http.ResponseWriter, r *http.Request) {
+	playlistName := r.URL.Query().Get("playlist")
+	selected, ok := views.Playlists[playlistName]
+	if !ok {
+		http.Error(w, "Unknown playlist: "+playlistName, http.StatusNotFound)
+		return
+	}
+
+	pinned := selected.PinOnCriticalHost && viewHasCriticalHost(r, selected.pinView())
+	viewName, remaining := selected.pinView(), 0
+	if !pinned {
+		var entry playlistEntry
+		entry, remaining = selected.scheduledView(now().Unix())
+		viewName = entry.View
+	}
+
+	pageVariables := buildPageVariables(viewRequest(r, viewName), problems)
+	pageVariables.View = viewName
+	// Reload in time to switch to the next view
+	if !pinned && remaining < pageVariables.RefreshInterval {
+		pageVariables.RefreshInterval = remaining
+	}
+	pageVariables.Playlist = playlistName
+	pageVariables.Pinned = pinned
+
+	renderPage(w, pageVariables)
+}
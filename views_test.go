@@ -0,0 +1,212 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func writeViewConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "views.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("unable to write view config: %v", err)
+	}
+	return path
+}
+
+func TestLoadViewConfig(t *testing.T) {
+	path := writeViewConfig(t, `{
+		"views": {
+			"criticals": {"query": "minState=2&maxState=2"},
+			"network": {"query": "hostGroup=network"}
+		},
+		"playlists": {
+			"ops": {"views": [{"view": "criticals", "seconds": 30}, {"view": "network", "seconds": 15}], "pin_on_critical_host": true}
+		}
+	}`)
+
+	config, err := loadViewConfig(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(config.Views) != 2 || config.Views["network"].Query != "hostGroup=network" {
+		t.Errorf("unexpected views: %+v", config.Views)
+	}
+	ops := config.Playlists["ops"]
+	if len(ops.Views) != 2 || !ops.PinOnCriticalHost || ops.pinView() != "criticals" {
+		t.Errorf("unexpected playlist: %+v", ops)
+	}
+
+	if config, err := loadViewConfig(""); err != nil || len(config.Views) != 0 {
+		t.Errorf("expected empty config without a file, got %+v (%v)", config, err)
+	}
+}
+
+func TestLoadViewConfig_Invalid(t *testing.T) {
+	tests := []string{
+		`{`,
+		`{"views": {"a": {"query": "%zz"}}}`,
+		`{"playlists": {"ops": {"views": []}}}`,
+		`{"playlists": {"ops": {"views": [{"view": "missing", "seconds": 10}]}}}`,
+		`{"views": {"a": {}}, "playlists": {"ops": {"views": [{"view": "a", "seconds": 0}]}}}`,
+		`{"views": {"a": {}}, "playlists": {"ops": {"views": [{"view": "a", "seconds": 10}], "pin_view": "missing"}}}`,
+	}
+
+	for _, content := range tests {
+		if _, err := loadViewConfig(writeViewConfig(t, content)); err == nil {
+			t.Errorf("expected error for %s", content)
+		}
+	}
+	if _, err := loadViewConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
+
+func TestWithView(t *testing.T) {
+	originalViews := views
+	defer func() { views = originalViews }()
+	views = viewConfig{Views: map[string]view{
+		"criticals": {Query: "minState=2&maxState=2&sort=duration"},
+	}}
+
+	query := withView(url.Values{"view": {"criticals"}, "sort": {"recent"}})
+	if query.Get("minState") != "2" || query.Get("maxState") != "2" {
+		t.Errorf("expected view parameters, got %v", query)
+	}
+	if query.Get("sort") != "recent" {
+		t.Errorf("expected explicit parameters to win, got %v", query)
+	}
+
+	query = withView(url.Values{"view": {"unknown"}, "sort": {"recent"}})
+	if len(query) != 2 || query.Get("minState") != "" {
+		t.Errorf("expected unknown views to be ignored, got %v", query)
+	}
+}
+
+func TestPlaylist_ScheduledView(t *testing.T) {
+	p := playlist{Views: []playlistEntry{{View: "a", Seconds: 30}, {View: "b", Seconds: 15}}}
+
+	tests := []struct {
+		unix      int64
+		view      string
+		remaining int
+	}{
+		{0, "a", 30},
+		{29, "a", 1},
+		{30, "b", 15},
+		{44, "b", 1},
+		{45, "a", 30},
+	}
+	for _, tt := range tests {
+		entry, remaining := p.scheduledView(tt.unix)
+		if entry.View != tt.view || remaining != tt.remaining {
+			t.Errorf("scheduledView(%d) = %s, %d; want %s, %d", tt.unix, entry.View, remaining, tt.view, tt.remaining)
+		}
+	}
+}
+
+func TestRenderKiosk(t *testing.T) {
+	originalClient := client
+	originalViews := views
+	originalNow := now
+	defer func() {
+		client = originalClient
+		views = originalViews
+		now = originalNow
+	}()
+
+	views = viewConfig{
+		Views: map[string]view{
+			"criticals": {Query: "minState=2"},
+			"network":   {Query: "hostGroup=network"},
+		},
+		Playlists: map[string]playlist{
+			"ops": {
+				Views:             []playlistEntry{{View: "criticals", Seconds: 30}, {View: "network", Seconds: 15}},
+				PinOnCriticalHost: true,
+			},
+		},
+	}
+	serviceCalls := 0
+	stub := stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{EnableNotifications: true},
+		cibStatus: &icinga2apiclient.CIBStatus{},
+		hosts: []icinga2apiclient.Host{
+			{Name: "core-sw-1", State: 1, StateType: 0, Groups: []string{"network"}},
+		},
+		serviceCalls: &serviceCalls,
+	}
+	client = stub
+	now = func() time.Time { return time.Unix(32, 0) }
+
+	rec := httptest.NewRecorder()
+	renderKiosk(rec, httptest.NewRequest(http.MethodGet, "/kiosk?playlist=ops", nil))
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "View: network<br/>") {
+		t.Errorf("expected scheduled view network, got %s", body)
+	}
+	if !strings.Contains(body, `content="5"`) {
+		t.Errorf("expected default refresh interval, got %s", body)
+	}
+	if serviceCalls != 1 {
+		t.Errorf("expected a single snapshot, got %d service queries", serviceCalls)
+	}
+
+	// A host down in a hard state pins the first view
+	stub.hosts[0].StateType = 1
+	client = stub
+
+	rec = httptest.NewRecorder()
+	renderKiosk(rec, httptest.NewRequest(http.MethodGet, "/kiosk?playlist=ops", nil))
+
+	body = rec.Body.String()
+	if !strings.Contains(body, "View: criticals (pinned)") {
+		t.Errorf("expected pinned view criticals, got %s", body)
+	}
+	if serviceCalls != 2 {
+		t.Errorf("expected a single snapshot for the pinned view, got %d service queries", serviceCalls-1)
+	}
+
+	rec = httptest.NewRecorder()
+	renderKiosk(rec, httptest.NewRequest(http.MethodGet, "/kiosk?playlist=missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for unknown playlist, got %d", rec.Code)
+	}
+}
+
+func TestRenderKiosk_RefreshesForNextView(t *testing.T) {
+	originalClient := client
+	originalViews := views
+	originalNow := now
+	defer func() {
+		client = originalClient
+		views = originalViews
+		now = originalNow
+	}()
+
+	views = viewConfig{
+		Views:     map[string]view{"a": {}, "b": {}},
+		Playlists: map[string]playlist{"ops": {Views: []playlistEntry{{View: "a", Seconds: 30}, {View: "b", Seconds: 15}}}},
+	}
+	client = stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{EnableNotifications: true},
+		cibStatus: &icinga2apiclient.CIBStatus{},
+	}
+	now = func() time.Time { return time.Unix(28, 0) }
+
+	rec := httptest.NewRecorder()
+	renderKiosk(rec, httptest.NewRequest(http.MethodGet, "/kiosk?playlist=ops", nil))
+
+	if !strings.Contains(rec.Body.String(), `content="2"`) {
+		t.Errorf("expected refresh right before the next view, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Board-level events sent to webhooks
+const (
+	eventNewCritical           = "new_critical"
+	eventAllClear              = "all_clear"
+	eventNotificationsDisabled = "notifications_disabled"
+	eventNotificationsEnabled  = "notifications_enabled"
+	eventHostsDown             = "hosts_down"
+	eventFeatureDisabled       = "feature_disabled"
+	eventFeatureEnabled        = "feature_enabled"
+)
+
+const defaultWebhookPayload = `{"event": {{json .Type}}, "title": {{json .Title}}, "time": {{json .Time}}}`
+
+// webhookConfig is read from the file configured in WEBHOOKS_FILE.
+//
+//	{
+//	  "hosts_down_threshold": 10,
+//	  "webhooks": [
+//	    {
+//	      "url": "https://chat.example.com/hooks/noc",
+//	      "events": ["new_critical", "all_clear", "hosts_down"],
+//	      "payload": "{\"text\": {{json .Title}}}"
+//	    }
+//	  ]
+//	}
+type webhookConfig struct {
+	// Send hosts_down when at least this many hosts are down, 0 disables the event
+	HostsDownThreshold int `json:"hosts_down_threshold"`
+	// Identical events are sent only once within this many seconds
+	DedupSeconds int       `json:"dedup_seconds"`
+	Webhooks     []webhook `json:"webhooks"`
+}
+
+type webhook struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Go template of the request body, rendered with a webhookEvent
+	Payload string            `json:"payload"`
+	Headers map[string]string `json:"headers"`
+	// Number of retries after a failed request
+	Retries *int `json:"retries"`
+
+	template *template.Template
+}
+
+// webhookEvent is the data available in payload templates.
+type webhookEvent struct {
+	Type  string
+	Title string
+	// RFC3339 time of the snapshot that triggered the event
+	Time string
+	// New problems for new_critical, cleared problems for all_clear
+	Problems  []exportRow
+	HostsDown int
+	Criticals int
+	BaseURL   string
+	// Global feature for feature_disabled and feature_enabled, e.g. "host_checks"
+	Feature string
+
+	dedupKey string
+}
+
+var webhookFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+}
+
+func isValidWebhookEvent(event string) bool {
+	return slices.Contains([]string{eventNewCritical, eventAllClear, eventNotificationsDisabled, eventNotificationsEnabled, eventHostsDown, eventFeatureDisabled, eventFeatureEnabled}, event)
+}
+
+func loadWebhookConfig(path string) (webhookConfig, error) {
+	config := webhookConfig{DedupSeconds: 600}
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	for i := range config.Webhooks {
+		hook := &config.Webhooks[i]
+		if hook.URL == "" {
+			return config, fmt.Errorf("webhook %d has no url", i)
+		}
+		for _, event := range hook.Events {
+			if !isValidWebhookEvent(event) {
+				return config, fmt.Errorf("webhook %s has an invalid event: %s", hook.URL, event)
+			}
+		}
+		if hook.Payload == "" {
+			hook.Payload = defaultWebhookPayload
+		}
+		hook.template, err = template.New(hook.URL).Funcs(webhookFuncs).Parse(hook.Payload)
+		if err != nil {
+			return config, fmt.Errorf("webhook %s has an invalid payload: %w", hook.URL, err)
+		}
+		if hook.Retries == nil {
+			retries := 3
+			hook.Retries = &retries
+		}
+	}
+
+	return config, nil
+}
+
+// boardState is the aggregated picture of a snapshot.
+type boardState struct {
+	// Hosts that are down and critical services
+	Problems map[string]exportRow
+	// Hosts that are down, not counting the unreachable ones
+	HostsDown             int
+	NotificationsDisabled bool
+	DisabledFeatures      []string
+}
+
+func newBoardState(pageVariables PageVariables) boardState {
+	state := boardState{
+		Problems:              make(map[string]exportRow),
+		NotificationsDisabled: pageVariables.NotificationsDisabled,
+		DisabledFeatures:      pageVariables.DisabledFeatures,
+	}
+	for _, row := range exportRows(pageVariables) {
+		// Unreachable hosts are collapsed under their parent, only the parent counts
+		if row.State == "Down" {
+			state.HostsDown++
+		}
+		if row.State == "Down" || row.State == "Critical" {
+			state.Problems[row.Host+"!"+row.Service] = row
+		}
+	}
+	return state
+}
+
+// boardEvents compares two successive states.
+func boardEvents(previous boardState, current boardState, hostsDownThreshold int) []webhookEvent {
+	var events []webhookEvent
+
+	var appeared []exportRow
+	for _, key := range slices.Sorted(maps.Keys(current.Problems)) {
+		if _, ok := previous.Problems[key]; !ok {
+			appeared = append(appeared, current.Problems[key])
+		}
+	}
+	if len(appeared) > 0 {
+		title := fmt.Sprintf("%d new critical problems", len(appeared))
+		if len(appeared) == 1 {
+			title = appeared[0].State + ": " + problemSubject(appeared[0])
+		}
+		events = append(events, webhookEvent{Type: eventNewCritical, Title: title, Problems: appeared})
+	}
+
+	if len(previous.Problems) > 0 && len(current.Problems) == 0 {
+		var cleared []exportRow
+		for _, key := range slices.Sorted(maps.Keys(previous.Problems)) {
+			cleared = append(cleared, previous.Problems[key])
+		}
+		events = append(events, webhookEvent{Type: eventAllClear, Title: "All clear", Problems: cleared})
+	}
+
+	if !previous.NotificationsDisabled && current.NotificationsDisabled {
+		events = append(events, webhookEvent{Type: eventNotificationsDisabled, Title: "Notifications are disabled globally"})
+	}
+	if previous.NotificationsDisabled && !current.NotificationsDisabled {
+		events = append(events, webhookEvent{Type: eventNotificationsEnabled, Title: "Notifications are enabled again"})
+	}
+
+	// Notifications have their own events above
+	for _, feature := range current.DisabledFeatures {
+		if feature != featureNotifications && !slices.Contains(previous.DisabledFeatures, feature) {
+			events = append(events, webhookEvent{Type: eventFeatureDisabled, Title: featureNames[feature] + " disabled globally", Feature: feature})
+		}
+	}
+	for _, feature := range previous.DisabledFeatures {
+		if feature != featureNotifications && !slices.Contains(current.DisabledFeatures, feature) {
+			events = append(events, webhookEvent{Type: eventFeatureEnabled, Title: featureNames[feature] + " enabled again", Feature: feature})
+		}
+	}
+
+	if hostsDownThreshold > 0 && previous.HostsDown < hostsDownThreshold && current.HostsDown >= hostsDownThreshold {
+		events = append(events, webhookEvent{Type: eventHostsDown, Title: fmt.Sprintf("%d hosts down", current.HostsDown)})
+	}
+
+	for i := range events {
+		events[i].HostsDown = current.HostsDown
+		events[i].Criticals = len(current.Problems)
+		// The same problems cause the same event, no matter when
+		keys := []string{events[i].Type}
+		if events[i].Feature != "" {
+			keys = append(keys, events[i].Feature)
+		}
+		for _, row := range events[i].Problems {
+			keys = append(keys, row.Host+"!"+row.Service)
+		}
+		events[i].dedupKey = strings.Join(keys, "|")
+	}
+	return events
+}
+
+func problemSubject(row exportRow) string {
+	if row.Service != "" {
+		return row.Service + " on " + row.Host
+	}
+	return row.Host
+}
+
+// webhookDispatcher turns successive snapshots into board-level events and
+// sends them to the configured webhooks.
+type webhookDispatcher struct {
+	config webhookConfig
+	client *http.Client
+	// Delay before the first retry, doubled for every further retry
+	backoff time.Duration
+
+	mu          sync.Mutex
+	initialized bool
+	previous    boardState
+	sent        map[string]time.Time
+	deliveries  sync.WaitGroup
+}
+
+func newWebhookDispatcher(config webhookConfig) *webhookDispatcher {
+	return &webhookDispatcher{
+		config:  config,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		backoff: time.Second,
+		sent:    make(map[string]time.Time),
+	}
+}
+
+// observe compares a snapshot with the previous one and sends the resulting
+// events in the background. The very first snapshot only sets the baseline.
+func (d *webhookDispatcher) observe(pageVariables PageVariables) {
+	// A failed snapshot misses problems, they didn't recover
+	if pageVariables.Error != nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	current := newBoardState(pageVariables)
+	previous, initialized := d.previous, d.initialized
+	d.previous, d.initialized = current, true
+	if !initialized {
+		return
+	}
+
+	currentTime := pageVariables.Time.Time
+	dedupWindow := time.Duration(d.config.DedupSeconds) * time.Second
+	for key, sentAt := range d.sent {
+		if currentTime.Sub(sentAt) >= dedupWindow {
+			delete(d.sent, key)
+		}
+	}
+
+	for _, event := range boardEvents(previous, current, d.config.HostsDownThreshold) {
+		if _, ok := d.sent[event.dedupKey]; ok {
+			continue
+		}
+		d.sent[event.dedupKey] = currentTime
+
+		event.Time = currentTime.UTC().Format(time.RFC3339)
+		event.BaseURL = pageVariables.BaseURL
+		for _, hook := range d.config.Webhooks {
+			if slices.Contains(hook.Events, event.Type) {
+				d.deliveries.Add(1)
+				go func(hook webhook, event webhookEvent) {
+					defer d.deliveries.Done()
+					if err := d.deliver(hook, event); err != nil {
+						fmt.Fprintf(logOutput, "Error sending %s to webhook %s: %v\n", event.Type, hook.URL, err)
+					}
+				}(hook, event)
+			}
+		}
+	}
+}
+
+// deliver posts the event to the webhook, retrying with exponential backoff.
+// Client errors other than 429 Too Many Requests are not retried.
+func (d *webhookDispatcher) deliver(hook webhook, event webhookEvent) error {
+	var payload bytes.Buffer
+	if err := hook.template.Execute(&payload, event); err != nil {
+		return err
+	}
+
+	var err error
+	delay := d.backoff
+	for attempt := 0; attempt <= *hook.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		var retry bool
+		retry, err = d.post(hook, payload.Bytes())
+		if err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+func (d *webhookDispatcher) post(hook webhook, payload []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range hook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookReceiver records the requests of a webhook and fails the first
+// failures of them.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	attempts int
+	bodies   []string
+	headers  []http.Header
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	rcv.attempts++
+	if rcv.failures > 0 {
+		rcv.failures--
+		w.WriteHeader(rcv.status)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	rcv.bodies = append(rcv.bodies, string(body))
+	rcv.headers = append(rcv.headers, r.Header)
+}
+
+func writeWebhookConfig(t *testing.T, config string) webhookConfig {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadWebhookConfig(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return loaded
+}
+
+func snapshotAt(minute int, hosts []PageHostListRecord, services []PageServiceListRecord, notificationsDisabled bool) PageVariables {
+	return PageVariables{
+		Time:                  timestamp{time.Date(2026, time.March, 11, 8, minute, 0, 0, time.UTC)},
+		HostRecords:           hosts,
+		ServiceRecords:        services,
+		NotificationsDisabled: notificationsDisabled,
+		BaseURL:               "https://icinga.example.test",
+	}
+}
+
+func TestLoadWebhookConfig(t *testing.T) {
+	config := writeWebhookConfig(t, `{"webhooks": [{"url": "http://localhost/hook", "events": ["all_clear"]}]}`)
+	hook := config.Webhooks[0]
+	if hook.Payload != defaultWebhookPayload || *hook.Retries != 3 || config.DedupSeconds != 600 {
+		t.Errorf("expected defaults, got %+v and %+v", config, hook)
+	}
+
+	for _, invalid := range []string{
+		`{"webhooks": [{"events": ["all_clear"]}]}`,
+		`{"webhooks": [{"url": "http://localhost/hook", "events": ["everything"]}]}`,
+		`{"webhooks": [{"url": "http://localhost/hook", "payload": "{{.Title"}]}`,
+	} {
+		path := filepath.Join(t.TempDir(), "webhooks.json")
+		os.WriteFile(path, []byte(invalid), 0o600)
+		if _, err := loadWebhookConfig(path); err == nil {
+			t.Errorf("expected error for %s", invalid)
+		}
+	}
+}
+
+func TestBoardEvents(t *testing.T) {
+	web1 := PageServiceListRecord{Name: "http", State: 2, StateType: 1, AggregatedHosts: []string{"web-1"}, AggregatedServices: []string{"http"}, Members: []PageServiceMember{{Host: "web-1", Service: "http"}}}
+	web2 := PageServiceListRecord{Name: "http", State: 2, StateType: 1, AggregatedHosts: []string{"web-2"}, AggregatedServices: []string{"http"}, Members: []PageServiceMember{{Host: "web-2", Service: "http"}}}
+	warning := PageServiceListRecord{Name: "disk", State: 1, StateType: 1, AggregatedHosts: []string{"db-1"}, AggregatedServices: []string{"disk"}, Members: []PageServiceMember{{Host: "db-1", Service: "disk"}}}
+	down := PageHostListRecord{Name: "core-sw-1", State: 1, StateType: 1, UnreachableHosts: []string{"web-3", "web-4"},
+		UnreachableMembers: []PageUnreachableHost{{Name: "web-3", StateType: 1}, {Name: "web-4", StateType: 1}}}
+	alsoDown := PageHostListRecord{Name: "db-1", State: 1, StateType: 1}
+
+	empty := newBoardState(snapshotAt(0, nil, []PageServiceListRecord{warning}, false))
+	critical := newBoardState(snapshotAt(1, []PageHostListRecord{down, alsoDown}, []PageServiceListRecord{web1, web2}, true))
+	if critical.HostsDown != 2 {
+		t.Errorf("expected the unreachable hosts not to count as down, got %d", critical.HostsDown)
+	}
+
+	events := boardEvents(empty, critical, 2)
+	types := []string{}
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	if len(events) != 3 || events[0].Type != eventNewCritical || events[1].Type != eventNotificationsDisabled || events[2].Type != eventHostsDown {
+		t.Fatalf("unexpected events %v", types)
+	}
+	if events[0].Title != "4 new critical problems" || len(events[0].Problems) != 4 {
+		t.Errorf("unexpected new_critical event %+v", events[0])
+	}
+	if events[2].Title != "2 hosts down" {
+		t.Errorf("unexpected hosts_down event %+v", events[2])
+	}
+
+	events = boardEvents(critical, empty, 2)
+	if len(events) != 2 || events[0].Type != eventAllClear || events[1].Type != eventNotificationsEnabled {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	if events[0].dedupKey != "all_clear|core-sw-1!|db-1!|web-1!http|web-2!http" {
+		t.Errorf("unexpected dedup key %q", events[0].dedupKey)
+	}
+}
+
+func TestWebhookDispatcherSendsEvents(t *testing.T) {
+	receiver := &webhookReceiver{failures: 2, status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	config := writeWebhookConfig(t, `{
+		"webhooks": [{
+			"url": "`+server.URL+`",
+			"events": ["new_critical"],
+			"payload": "{\"text\": {{json .Title}}, \"hosts\": [{{range $i, $p := .Problems}}{{if $i}}, {{end}}{{json $p.Host}}{{end}}]}",
+			"headers": {"X-Token": "secret"}
+		}]
+	}`)
+	dispatcher := newWebhookDispatcher(config)
+	dispatcher.backoff = time.Millisecond
+
+	critical := PageServiceListRecord{Name: "http", State: 2, StateType: 1, AggregatedHosts: []string{"web-1"}, AggregatedServices: []string{"http"}, Members: []PageServiceMember{{Host: "web-1", Service: "http"}}}
+	dispatcher.observe(snapshotAt(0, nil, nil, false))
+	dispatcher.observe(snapshotAt(1, nil, []PageServiceListRecord{critical}, false))
+	dispatcher.observe(snapshotAt(2, nil, nil, false))
+	// The same problem again within the dedup window
+	dispatcher.observe(snapshotAt(3, nil, []PageServiceListRecord{critical}, false))
+	dispatcher.deliveries.Wait()
+
+	if receiver.attempts != 3 {
+		t.Errorf("expected 2 retries, got %d attempts", receiver.attempts)
+	}
+	if len(receiver.bodies) != 1 {
+		t.Fatalf("expected 1 delivered event, got %v", receiver.bodies)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(receiver.bodies[0]), &payload); err != nil {
+		t.Fatalf("expected JSON payload, got %s", receiver.bodies[0])
+	}
+	if payload["text"] != "Critical: http on web-1" {
+		t.Errorf("unexpected payload %s", receiver.bodies[0])
+	}
+	if receiver.headers[0].Get("X-Token") != "secret" || receiver.headers[0].Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected headers %v", receiver.headers[0])
+	}
+}
+
+func TestWebhookDispatcherDoesNotRetryClientErrors(t *testing.T) {
+	receiver := &webhookReceiver{failures: 5, status: http.StatusBadRequest}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	config := writeWebhookConfig(t, `{"webhooks": [{"url": "`+server.URL+`", "events": ["notifications_disabled"]}]}`)
+	dispatcher := newWebhookDispatcher(config)
+	dispatcher.backoff = time.Millisecond
+
+	dispatcher.observe(snapshotAt(0, nil, nil, false))
+	dispatcher.observe(snapshotAt(1, nil, nil, true))
+	dispatcher.deliveries.Wait()
+
+	if receiver.attempts != 1 {
+		t.Errorf("expected no retries for 400 Bad Request, got %d attempts", receiver.attempts)
+	}
+}
+
+func TestWebhookDispatcherIgnoresFailedServiceFetch(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	config := writeWebhookConfig(t, `{"webhooks": [{"url": "`+server.URL+`", "events": ["new_critical", "all_clear"]}]}`)
+	dispatcher := newWebhookDispatcher(config)
+
+	stub := apiTestClient()
+	stub.hosts = nil
+	withAPITestClient(t, stub)
+	snapshot := func() PageVariables {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		return buildPageVariables(completeRequest(req), nil)
+	}
+
+	dispatcher.observe(snapshot())
+	stub.servicesErr = errors.New("connection reset")
+	client = stub
+	dispatcher.observe(snapshot())
+	stub.servicesErr = nil
+	client = stub
+	dispatcher.observe(snapshot())
+	dispatcher.deliveries.Wait()
+
+	if receiver.attempts != 0 {
+		t.Errorf("expected no all_clear and new_critical for a failed service fetch, got %v", receiver.bodies)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+// maxWeightLevels is the number of weight-<level> classes in assets/style.css.
+const maxWeightLevels = 4
+
+// severityRule adds Weight to every service matching it.
+type severityRule struct {
+	// One of "state", "statetype" or "vars"
+	Kind string
+	// Name of the custom var, only used for Kind "vars"
+	Path   string
+	Value  string
+	Weight int
+}
+
+// severityWeighting computes a severity for services, combining their state
+// with custom vars like vars.priority or vars.business_impact.
+type severityWeighting struct {
+	Rules []severityRule
+	// Minimum weight for each color level, ascending
+	Thresholds []int
+}
+
+// parseSeverityWeighting parses rules like "state.2=30,vars.priority.P1=40"
+// and thresholds like "20,40,60".
+func parseSeverityWeighting(rules string, thresholds string) (severityWeighting, error) {
+	var weighting severityWeighting
+
+	for _, rule := range splitList(rules) {
+		key, weightString, found := strings.Cut(rule, "=")
+		if !found {
+			return weighting, fmt.Errorf("invalid rule %q, expected <key>=<weight>", rule)
+		}
+		weight, err := strconv.Atoi(weightString)
+		if err != nil {
+			return weighting, fmt.Errorf("invalid weight in rule %q", rule)
+		}
+
+		kind, rest, _ := strings.Cut(key, ".")
+		parsed := severityRule{Kind: kind, Weight: weight}
+		switch kind {
+		case "state", "statetype":
+			parsed.Value = rest
+		case "vars":
+			// The value is everything after the last dot, so var names may be nested
+			index := strings.LastIndex(rest, ".")
+			if index <= 0 || index == len(rest)-1 {
+				return weighting, fmt.Errorf("invalid rule %q, expected vars.<name>.<value>=<weight>", rule)
+			}
+			parsed.Path = rest[:index]
+			parsed.Value = rest[index+1:]
+		default:
+			return weighting, fmt.Errorf("invalid rule %q, key has to start with state, statetype or vars", rule)
+		}
+		if parsed.Value == "" {
+			return weighting, fmt.Errorf("invalid rule %q, missing value", rule)
+		}
+		weighting.Rules = append(weighting.Rules, parsed)
+	}
+
+	for _, threshold := range splitList(thresholds) {
+		value, err := strconv.Atoi(threshold)
+		if err != nil {
+			return weighting, fmt.Errorf("invalid threshold %q", threshold)
+		}
+		if len(weighting.Thresholds) > 0 && value <= weighting.Thresholds[len(weighting.Thresholds)-1] {
+			return weighting, fmt.Errorf("thresholds have to be ascending")
+		}
+		weighting.Thresholds = append(weighting.Thresholds, value)
+	}
+	if len(weighting.Thresholds) > maxWeightLevels {
+		return weighting, fmt.Errorf("at most %d thresholds are supported", maxWeightLevels)
+	}
+
+	return weighting, nil
+}
+
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// weigh returns the sum of the weights of all rules matching the service.
+func (w severityWeighting) weigh(service icinga2apiclient.Service) int {
+	weight := 0
+	for _, rule := range w.Rules {
+		var value string
+		switch rule.Kind {
+		case "state":
+			value = strconv.Itoa(service.State)
+		case "statetype":
+			value = strconv.Itoa(service.StateType)
+		case "vars":
+			varValue, ok := lookupVar(service.Vars, rule.Path)
+			if !ok {
+				continue
+			}
+			value = fmt.Sprint(varValue)
+		}
+		if value == rule.Value {
+			weight += rule.Weight
+		}
+	}
+	return weight
+}
+
+// level maps a weight onto the configured thresholds. It returns 0 if the
+// weight is below the first threshold, 1 if it reaches the first one and so on.
+func (w severityWeighting) level(weight int) int {
+	level := 0
+	for i, threshold := range w.Thresholds {
+		if weight >= threshold {
+			level = i + 1
+		}
+	}
+	return level
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func TestParseSeverityWeighting(t *testing.T) {
+	weighting, err := parseSeverityWeighting("state.2=30, statetype.1=10,vars.business_impact.high=20,vars.owner.team.ops=5", "20, 40")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []severityRule{
+		{Kind: "state", Value: "2", Weight: 30},
+		{Kind: "statetype", Value: "1", Weight: 10},
+		{Kind: "vars", Path: "business_impact", Value: "high", Weight: 20},
+		{Kind: "vars", Path: "owner.team", Value: "ops", Weight: 5},
+	}
+	if len(weighting.Rules) != len(expected) {
+		t.Fatalf("expected %d rules, got %+v", len(expected), weighting.Rules)
+	}
+	for i, rule := range expected {
+		if weighting.Rules[i] != rule {
+			t.Errorf("rule %d = %+v, want %+v", i, weighting.Rules[i], rule)
+		}
+	}
+	if len(weighting.Thresholds) != 2 || weighting.Thresholds[0] != 20 || weighting.Thresholds[1] != 40 {
+		t.Errorf("unexpected thresholds: %v", weighting.Thresholds)
+	}
+}
+
+func TestParseSeverityWeighting_Invalid(t *testing.T) {
+	tests := []struct {
+		rules      string
+		thresholds string
+	}{
+		{"state.2", ""},
+		{"state.2=high", ""},
+		{"host.2=10", ""},
+		{"vars.priority=10", ""},
+		{"vars.priority.=10", ""},
+		{"state.=10", ""},
+		{"", "20,ten"},
+		{"", "40,20"},
+		{"", "10,20,30,40,50"},
+	}
+
+	for _, tt := range tests {
+		if _, err := parseSeverityWeighting(tt.rules, tt.thresholds); err == nil {
+			t.Errorf("expected error for rules %q and thresholds %q", tt.rules, tt.thresholds)
+		}
+	}
+}
+
+func TestSeverityWeighting_Weigh(t *testing.T) {
+	weighting, err := parseSeverityWeighting("state.1=10,state.2=30,statetype.1=5,vars.priority.P1=40,vars.business_impact.high=20", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		service  icinga2apiclient.Service
+		expected int
+	}{
+		{icinga2apiclient.Service{State: 1, StateType: 0}, 10},
+		{icinga2apiclient.Service{State: 2, StateType: 1}, 35},
+		{icinga2apiclient.Service{State: 1, StateType: 1, Vars: map[string]interface{}{"priority": "P1"}}, 55},
+		{icinga2apiclient.Service{State: 2, StateType: 1, Vars: map[string]interface{}{"priority": "P1", "business_impact": "high"}}, 95},
+		{icinga2apiclient.Service{State: 2, StateType: 1, Vars: map[string]interface{}{"priority": "P2"}}, 35},
+	}
+
+	for _, tt := range tests {
+		if weight := weighting.weigh(tt.service); weight != tt.expected {
+			t.Errorf("weigh(%+v) = %d, want %d", tt.service, weight, tt.expected)
+		}
+	}
+}
+
+func TestSeverityWeighting_Level(t *testing.T) {
+	weighting := severityWeighting{Thresholds: []int{40, 60}}
+
+	tests := map[int]int{0: 0, 39: 0, 40: 1, 59: 1, 60: 2, 100: 2}
+	for weight, expected := range tests {
+		if level := weighting.level(weight); level != expected {
+			t.Errorf("level(%d) = %d, want %d", weight, level, expected)
+		}
+	}
+
+	if (severityWeighting{}).level(100) != 0 {
+		t.Errorf("expected level 0 without thresholds")
+	}
+}
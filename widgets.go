@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+// infoBarWidgets compute the value of a widget from the CIB status. The
+// value is formatted with the message "widget.<name>" of the catalog.
+var infoBarWidgets = map[string]func(status *icinga2apiclient.CIBStatus) interface{}{
+	"avg_latency":        func(s *icinga2apiclient.CIBStatus) interface{} { return s.AvgLatency },
+	"max_latency":        func(s *icinga2apiclient.CIBStatus) interface{} { return s.MaxLatency },
+	"avg_execution_time": func(s *icinga2apiclient.CIBStatus) interface{} { return s.AvgExecutionTime },
+	"max_execution_time": func(s *icinga2apiclient.CIBStatus) interface{} { return s.MaxExecutionTime },
+	"active_checks": func(s *icinga2apiclient.CIBStatus) interface{} {
+		return int(s.ActiveHostChecks1Min + s.ActiveServiceChecks1Min)
+	},
+	"passive_checks": func(s *icinga2apiclient.CIBStatus) interface{} {
+		return int(s.PassiveHostChecks1Min + s.PassiveServiceChecks1Min)
+	},
+	"concurrent_checks":     func(s *icinga2apiclient.CIBStatus) interface{} { return int(s.CurrentConcurrentChecks) },
+	"remote_check_queue":    func(s *icinga2apiclient.CIBStatus) interface{} { return int(s.RemoteCheckQueue) },
+	"hosts_unreachable":     func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumHostsUnreachable },
+	"hosts_pending":         func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumHostsPending },
+	"hosts_flapping":        func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumHostsFlapping },
+	"hosts_in_downtime":     func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumHostsInDowntime },
+	"hosts_acknowledged":    func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumHostsAcknowledged },
+	"services_unreachable":  func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumServicesUnreachable },
+	"services_pending":      func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumServicesPending },
+	"services_flapping":     func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumServicesFlapping },
+	"services_in_downtime":  func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumServicesInDowntime },
+	"services_acknowledged": func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumServicesAcknowledged },
+	"services_handled":      func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumServicesHandled },
+	"hosts_unhandled":       func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumHostsUnhandled() },
+	"services_unhandled":    func(s *icinga2apiclient.CIBStatus) interface{} { return s.NumServicesUnhandled() },
+	"hosts_handled_percent": func(s *icinga2apiclient.CIBStatus) interface{} {
+		return icinga2apiclient.RoundPercent(s.PercentHostsHandled())
+	},
+	"services_handled_percent": func(s *icinga2apiclient.CIBStatus) interface{} {
+		return icinga2apiclient.RoundPercent(s.PercentServicesHandled())
+	},
+}
+
+type widget struct {
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+// parseWidgets parses a comma separated list of widget names like "avg_latency,active_checks".
+func parseWidgets(value string) ([]string, error) {
+	names := splitList(value)
+	for _, name := range names {
+		if _, ok := infoBarWidgets[name]; !ok {
+			return nil, fmt.Errorf("unknown widget: %s", name)
+		}
+	}
+	return names, nil
+}
+
+func renderWidgets(names []string, status *icinga2apiclient.CIBStatus, messages localizer) []widget {
+	if status == nil {
+		return nil
+	}
+	var widgets []widget
+	for _, name := range names {
+		widgets = append(widgets, widget{
+			Name: name,
+			Text: messages.T("widget."+name, infoBarWidgets[name](status)),
+		})
+	}
+	return widgets
+}
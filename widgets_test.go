@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hujiko/icinga-dashboard/icinga2apiclient"
+)
+
+func TestParseWidgets(t *testing.T) {
+	names, err := parseWidgets(" avg_latency, active_checks ,")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := []string{"avg_latency", "active_checks"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+
+	if _, err := parseWidgets("avg_latency,load"); err == nil || !strings.Contains(err.Error(), "load") {
+		t.Errorf("expected an error for an unknown widget, got %v", err)
+	}
+}
+
+func TestRenderWidgets(t *testing.T) {
+	status := &icinga2apiclient.CIBStatus{
+		AvgLatency:              0.8123,
+		ActiveHostChecks1Min:    21,
+		ActiveServiceChecks1Min: 144,
+		NumServicesHandled:      4,
+	}
+
+	status.NumServicesProblem = 12
+	widgets := renderWidgets([]string{"avg_latency", "active_checks", "services_handled", "services_unhandled", "services_handled_percent"}, status, newLocalizer("en"))
+	want := []widget{
+		{Name: "avg_latency", Text: "avg check latency 0.8s"},
+		{Name: "active_checks", Text: "165 active checks/min"},
+		{Name: "services_handled", Text: "4 services handled"},
+		{Name: "services_unhandled", Text: "8 services unhandled"},
+		{Name: "services_handled_percent", Text: "33.3% of service problems handled"},
+	}
+	if !reflect.DeepEqual(widgets, want) {
+		t.Errorf("expected %+v, got %+v", want, widgets)
+	}
+
+	if widgets := renderWidgets([]string{"avg_latency"}, nil, newLocalizer("en")); widgets != nil {
+		t.Errorf("expected no widgets without CIB status, got %+v", widgets)
+	}
+}
+
+func TestWidgetsHaveMessages(t *testing.T) {
+	for name := range infoBarWidgets {
+		for language, messages := range catalogs {
+			if _, ok := messages["widget."+name]; !ok {
+				t.Errorf("missing message widget.%s in %s", name, language)
+			}
+		}
+	}
+}
+
+func TestRenderDashboardShowsWidgets(t *testing.T) {
+	stub := apiTestClient()
+	stub.cibStatus.AvgLatency = 0.8
+	withAPITestClient(t, stub)
+
+	rec := httptest.NewRecorder()
+	renderDashboard(rec, httptest.NewRequest(http.MethodGet, "/?widgets=avg_latency", nil))
+	if !strings.Contains(rec.Body.String(), `<span class="info-bar-widget widget-avg_latency">avg check latency 0.8s</span>`) {
+		t.Errorf("expected latency widget, got %s", rec.Body.String())
+	}
+	// 9 of 10 hosts up, 40 of 43 services OK
+	for _, expected := range []string{">90.0%</td>", ">93.0%</td>"} {
+		if !strings.Contains(rec.Body.String(), expected) {
+			t.Errorf("expected percentage %q, got %s", expected, rec.Body.String())
+		}
+	}
+
+	first := httptest.NewRecorder()
+	renderJSON(first, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard?widgets=avg_latency", nil))
+	stub.cibStatus.AvgLatency = 1.4
+	second := httptest.NewRecorder()
+	renderJSON(second, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard?widgets=avg_latency", nil))
+	if first.Header().Get("ETag") != second.Header().Get("ETag") {
+		t.Errorf("expected the ETag to ignore check statistics, got %s and %s", first.Header().Get("ETag"), second.Header().Get("ETag"))
+	}
+	if !strings.Contains(second.Body.String(), `"avg_latency":1.4`) {
+		t.Errorf("expected check statistics in JSON, got %s", second.Body.String())
+	}
+}
+
+func TestRenderDashboardShowsStatePercentages(t *testing.T) {
+	withAPITestClient(t, stubDashboardClient{
+		appStatus: &icinga2apiclient.IcingaApplication{EnableNotifications: true, EnableHostChecks: true, EnableServiceChecks: true},
+		cibStatus: &icinga2apiclient.CIBStatus{
+			NumHostsUp: 8, NumHostsDown: 1, NumHostsUnreachable: 1, NumHostsProblem: 2, NumHostsHandled: 1,
+			NumServicesOk: 15, NumServicesWarning: 1, NumServicesCritical: 3, NumServicesUnknown: 1,
+			NumServicesProblem: 5, NumServicesHandled: 1,
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	renderDashboard(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	body := rec.Body.String()
+	for _, expected := range []string{
+		`1 <span class="stats-percent">10.0%</span>`,
+		`3 <span class="stats-percent">15.0%</span>`,
+		`<td class="stats stats-value stats-handled">50.0% handled</td>`,
+		`<td class="stats stats-value stats-handled">20.0% handled</td>`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in dashboard, got %s", expected, body)
+		}
+	}
+}